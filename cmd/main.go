@@ -9,17 +9,27 @@ import (
 	"TestEffectiveMobile/internal/config"
 	"TestEffectiveMobile/internal/handler"
 	"TestEffectiveMobile/internal/repository"
+	"TestEffectiveMobile/internal/songinfo"
 	"TestEffectiveMobile/internal/usecase"
 	"TestEffectiveMobile/migrations"
+	"context"
 	"database/sql"
+	"errors"
 	"github.com/gorilla/mux"
 	_ "github.com/lib/pq"
 	httpSwagger "github.com/swaggo/http-swagger"
 	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 )
 
+// shutdownTimeout время на завершение запросов при остановке сервера
+const shutdownTimeout = 10 * time.Second
+
 func main() {
 	const op = "cmd.server.main"
 
@@ -48,26 +58,145 @@ func main() {
 	}
 
 	// Инициализация всех слоёв
-	songRepo := repository.NewSongRepository(db)
-	songUC := usecase.NewSongUseCase(songRepo, config.GetExternalAPIURL())
+	timeouts := config.GetTimeouts()
+	externalAPI := config.GetExternalAPI()
+	enrichment := config.GetEnrichment()
+
+	// У каждого источника обогащения свои повторы и свой выключатель
+	providers := make([]usecase.EnrichmentProvider, 0, len(enrichment.Providers))
+	for _, provider := range enrichment.Providers {
+		providers = append(providers, usecase.EnrichmentProvider{
+			Name: provider.Name,
+			Enricher: songinfo.NewClient(
+				provider.URL,
+				timeouts.ExternalAPI,
+				songinfo.RetryPolicy{
+					MaxRetries: externalAPI.Retries,
+					BaseDelay:  externalAPI.RetryBaseDelay,
+					MaxDelay:   externalAPI.RetryMaxDelay,
+				},
+				songinfo.NewCircuitBreaker(externalAPI.BreakerThreshold, externalAPI.BreakerTimeout),
+			),
+		})
+	}
+	registry, err := usecase.NewEnricherRegistry(providers, usecase.EnrichmentMode(enrichment.Mode), enrichment.Merge)
+	if err != nil {
+		slog.Error(op, "Ошибка настройки источников обогащения", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	enrichmentCacheRepo := repository.NewEnrichmentCacheRepository(db, timeouts.DB)
+	// Общий запрос к источникам должен успеть до того, как задачу обогащения заберёт другой воркер
+	enricher := usecase.NewCachedEnricher(
+		registry,
+		enrichmentCacheRepo,
+		enrichment.CacheTTL,
+		enrichment.CacheNotFoundTTL,
+		enrichment.JobLease,
+	)
+
+	songRepo := repository.NewSongRepository(db, timeouts.DB, timeouts.Export)
+	songUC := usecase.NewSongUseCase(
+		songRepo,
+		config.GetTrashRetention(),
+		config.GetImportConcurrency(),
+		timeouts.Import,
+	)
 	songHandler := handler.NewSongHandler(songUC)
 
+	artistRepo := repository.NewArtistRepository(db, timeouts.DB)
+	artistUC := usecase.NewArtistUseCase(artistRepo, songRepo)
+	artistHandler := handler.NewArtistHandler(artistUC)
+
+	revisionRepo := repository.NewRevisionRepository(db, timeouts.DB)
+	revisionUC := usecase.NewRevisionUseCase(revisionRepo, songRepo)
+	revisionHandler := handler.NewRevisionHandler(revisionUC)
+
+	enrichmentRepo := repository.NewEnrichmentRepository(db, timeouts.DB)
+	enrichmentUC := usecase.NewEnrichmentUseCase(
+		enrichmentRepo,
+		enricher,
+		enrichmentCacheRepo,
+		enrichment.Workers,
+		enrichment.MaxAttempts,
+		enrichment.PollInterval,
+		enrichment.RetryDelay,
+		enrichment.JobLease,
+	)
+	enrichmentHandler := handler.NewEnrichmentHandler(enrichmentUC)
+
 	// Настройка маршрутов
 	r := mux.NewRouter()
+	r.Use(handler.RequestID)
+	r.NotFoundHandler = handler.RequestID(http.HandlerFunc(handler.NotFound))
+	r.MethodNotAllowedHandler = handler.RequestID(http.HandlerFunc(handler.MethodNotAllowed))
+
+	r.HandleFunc("/songs", songHandler.ListSongs).Methods("GET")                 // Получение списка песен с фильтрацией и пагинацией
+	r.HandleFunc("/songs/search", songHandler.SearchSongs).Methods("GET")        // Полнотекстовый поиск по песням
+	r.HandleFunc("/songs/import", songHandler.ImportSongs).Methods("POST")       // Массовый импорт песен из CSV или NDJSON
+	r.HandleFunc("/songs/export", songHandler.ExportSongs).Methods("GET")        // Выгрузка песен в CSV, NDJSON или JSON
+	r.HandleFunc("/songs/duplicates", songHandler.ListDuplicates).Methods("GET") // Отчёт о похожих песнях
+	r.HandleFunc("/songs/{id}", songHandler.GetSong).Methods("GET", "HEAD")      // Получение песни со связанными данными
+	r.HandleFunc("/songs/{id}", songHandler.DeleteSong).Methods("DELETE")        // Удаление песни
+	r.HandleFunc("/songs/{id}", songHandler.UpdateSong).Methods("PUT")           // Изменение данных песни
+	r.HandleFunc("/songs/{id}", songHandler.PatchSong).Methods("PATCH")          // Частичное изменение данных песни
+	r.HandleFunc("/songs", songHandler.CreateSong).Methods("POST")               // Добавление новой песни с обогащения
+	r.HandleFunc("/songs/{id}/text", songHandler.GetSongText).Methods("GET")     // Получение текста песни с пагинацией
+	r.HandleFunc("/songs/{id}/merge", songHandler.MergeSongs).Methods("POST")    // Объединение похожих песен
+	r.HandleFunc("/songs/{id}/restore", songHandler.RestoreSong).Methods("POST") // Восстановление песни из корзины
+	r.HandleFunc("/trash/songs", songHandler.ListTrash).Methods("GET")           // Получение песен из корзины
+	r.HandleFunc("/admin/trash/purge", songHandler.PurgeTrash).Methods("POST")   // Окончательное удаление старых песен из корзины
+
+	r.HandleFunc("/songs/{id}/enrichment", enrichmentHandler.GetSongEnrichment).Methods("GET")     // Статус обогащения песни
+	r.HandleFunc("/admin/enrichment/jobs/failed", enrichmentHandler.ListFailedJobs).Methods("GET") // Задачи обогащения с ошибкой
+	r.HandleFunc("/admin/enrichment/jobs/{id}/retry", enrichmentHandler.RetryJob).Methods("POST")  // Повтор задачи обогащения
+	r.HandleFunc("/admin/enrichment/upstream", enrichmentHandler.GetUpstreamStatus).Methods("GET") // Состояние источников обогащения
+
+	r.HandleFunc("/admin/enrichment/cache", enrichmentHandler.ListCacheEntries).Methods("GET")         // Сохранённые ответы источников обогащения
+	r.HandleFunc("/admin/enrichment/cache", enrichmentHandler.InvalidateCache).Methods("DELETE")       // Удаление сохранённых ответов
+	r.HandleFunc("/admin/enrichment/cache/purge", enrichmentHandler.PurgeExpiredCache).Methods("POST") // Удаление истёкших ответов
+	r.HandleFunc("/admin/enrichment/cache/{id}", enrichmentHandler.DeleteCacheEntry).Methods("DELETE") // Удаление сохранённого ответа
+
+	r.HandleFunc("/songs/{id}/revisions", revisionHandler.ListRevisions).Methods("GET")                   // История изменений песни
+	r.HandleFunc("/songs/{id}/revisions/diff", revisionHandler.DiffRevisions).Methods("GET")              // Сравнение текста двух ревизий
+	r.HandleFunc("/songs/{id}/revisions/{rev:[0-9]+}", revisionHandler.GetRevision).Methods("GET")        // Получение ревизии
+	r.HandleFunc("/songs/{id}/revisions/{rev:[0-9]+}/revert", revisionHandler.RevertSong).Methods("POST") // Откат к ревизии
 
-	r.HandleFunc("/songs", songHandler.ListSongs).Methods("GET")             // Получение списка песен с фильтрацией и пагинацией
-	r.HandleFunc("/songs/{id}", songHandler.DeleteSong).Methods("DELETE")    // Удаление песни
-	r.HandleFunc("/songs/{id}", songHandler.UpdateSong).Methods("PUT")       // Изменение данных песни
-	r.HandleFunc("/songs", songHandler.CreateSong).Methods("POST")           // Добавление новой песни с обогащения
-	r.HandleFunc("/songs/{id}/text", songHandler.GetSongText).Methods("GET") // Получение текста песни с пагинацией
+	r.HandleFunc("/artists", artistHandler.ListArtists).Methods("GET")                // Получение списка исполнителей
+	r.HandleFunc("/artists", artistHandler.CreateArtist).Methods("POST")              // Добавление исполнителя
+	r.HandleFunc("/artists/{id}", artistHandler.GetArtist).Methods("GET")             // Получение исполнителя
+	r.HandleFunc("/artists/{id}", artistHandler.UpdateArtist).Methods("PUT")          // Изменение исполнителя
+	r.HandleFunc("/artists/{id}", artistHandler.DeleteArtist).Methods("DELETE")       // Удаление исполнителя
+	r.HandleFunc("/artists/{id}/songs", artistHandler.ListArtistSongs).Methods("GET") // Получение песен исполнителя
 
 	r.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler) // Маршрут для Swagger UI
 
+	// Воркеры обогащения и сервер останавливаются по SIGINT или SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var workers sync.WaitGroup
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		enrichmentUC.Run(ctx)
+	}()
+
 	// Запуск HTTP-сервера
 	port := os.Getenv("PORT")
+	server := &http.Server{Addr: ":" + port, Handler: r}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			slog.Error(op, "Ошибка остановки сервера", slog.String("error", err.Error()))
+		}
+	}()
+
 	slog.Info("Сервер запускается на порту: " + port)
-	if err := http.ListenAndServe(":"+port, r); err != nil {
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		slog.Error(op, "Ошибка сервера", slog.String("error", err.Error()))
-		return
+		stop()
 	}
+	workers.Wait()
 }
@@ -0,0 +1,52 @@
+// Command mockinfo имитирует внешнее API информации о песнях для локальной разработки.
+//
+// Запуск:
+//
+//	go run ./cmd/mockinfo -addr :8090 -fixtures cmd/mockinfo/fixtures
+//
+// и EXTERNAL_URL=http://localhost:8090/info в .env сервиса. Песни берутся из файлов .json, .yaml и .yml
+// каталога фикстур, неизвестная песня получает 404. Сбои включаются запросом
+//
+//	curl -X POST localhost:8090/_mock/faults -d '{"status": 503, "count": 3, "retryAfter": "2s"}'
+//
+// (поля: group, song, latency, status, retryAfter, malformed, count), список сбоев GET /_mock/faults,
+// сброс DELETE /_mock/faults, счётчик запросов GET /_mock/stats.
+package main
+
+import (
+	"TestEffectiveMobile/internal/mockinfo"
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+)
+
+func main() {
+	const op = "cmd.mockinfo.main"
+
+	addr := flag.String("addr", ":8090", "адрес сервера")
+	fixturesDir := flag.String("fixtures", "cmd/mockinfo/fixtures", "каталог фикстур")
+	latency := flag.Duration("latency", 0, "задержка каждого ответа")
+	flag.Parse()
+
+	fixtures, err := mockinfo.LoadFixtures(*fixturesDir)
+	if err != nil {
+		slog.Error(op, "Ошибка загрузки фикстур", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	handler := mockinfo.NewHandler(fixtures)
+	handler.SetLatency(*latency)
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	slog.Info(op+": имитация внешнего API запущена", "addr", *addr, "fixtures", len(fixtures))
+	if err = server.ListenAndServe(); err != nil {
+		slog.Error(op, "Ошибка запуска сервера", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+}
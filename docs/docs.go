@@ -15,32 +15,32 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/songs": {
+        "/admin/enrichment/cache": {
             "get": {
-                "description": "Получает список песен с возможностью фильтрации по группе и названию, а также с пагинацией.",
+                "description": "Возвращает сохранённые ответы источников обогащения, начиная с последнего, в том числе истёкшие.\nЗаписи с found=false запоминают, что источники не знают песню.\nГруппа и название сравниваются без учёта регистра и пробелов по краям.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "songs"
+                    "enrichment"
                 ],
-                "summary": "Получение списка песен",
+                "summary": "Сохранённые ответы источников обогащения",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Название группы",
+                        "description": "Группа",
                         "name": "group",
                         "in": "query"
                     },
                     {
                         "type": "string",
                         "description": "Название песни",
-                        "name": "song_title",
+                        "name": "song",
                         "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "Лимит записей (по умолчанию 11)",
+                        "description": "Лимит записей (по умолчанию 11, не больше 1000)",
                         "name": "limit",
                         "in": "query"
                     },
@@ -53,54 +53,70 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Список песен",
+                        "description": "Список записей",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/entities.Song"
+                                "$ref": "#/definitions/entities.EnrichmentCacheEntry"
                             }
                         }
                     },
+                    "400": {
+                        "description": "Неверные параметры пагинации",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
                     "500": {
-                        "description": "Ошибка сервера",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/entities.ErrorResponse"
                         }
                     }
                 }
             },
-            "post": {
-                "description": "Создает новую песню, выполняет вызов внешнего API для обогащения данных и сохраняет в базу.",
-                "consumes": [
-                    "application/json"
-                ],
+            "delete": {
+                "description": "Удаляет сохранённые ответы источников обогащения для группы и (или) названия песни.\nЧтобы удалить все ответы, передайте all=true без group и song.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "songs"
+                    "enrichment"
                 ],
-                "summary": "Добавление новой песни",
+                "summary": "Удаление сохранённых ответов",
                 "parameters": [
                     {
-                        "description": "Данные новой песни",
+                        "type": "string",
+                        "description": "Группа",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название песни",
                         "name": "song",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/entities.Song"
-                        }
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Удалить все ответы",
+                        "name": "all",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Созданная песня",
+                    "200": {
+                        "description": "Количество удалённых записей",
                         "schema": {
-                            "$ref": "#/definitions/entities.Song"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "integer",
+                                "format": "int64"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Не указаны group, song или all",
                         "schema": {
                             "$ref": "#/definitions/entities.ErrorResponse"
                         }
@@ -114,46 +130,113 @@ const docTemplate = `{
                 }
             }
         },
-        "/songs/{id}": {
-            "put": {
-                "description": "Обновляет данные песни по идентификатору. Передаётся JSON объект песни.",
-                "consumes": [
-                    "application/json"
-                ],
+        "/admin/enrichment/cache/purge": {
+            "post": {
+                "description": "Удаляет сохранённые ответы источников обогащения, срок которых истёк (ENRICHMENT_CACHE_TTL, ENRICHMENT_CACHE_NOT_FOUND_TTL).\nИстёкшие ответы не используются, но остаются в таблице, пока их не удалить.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "songs"
+                    "admin"
                 ],
-                "summary": "Обновление данных песни",
+                "summary": "Очистка истёкших ответов",
+                "responses": {
+                    "200": {
+                        "description": "Количество удалённых записей",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "integer",
+                                "format": "int64"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/enrichment/cache/{id}": {
+            "delete": {
+                "description": "Удаляет сохранённый ответ источников обогащения, следующий запрос песни обратится к источникам.",
+                "tags": [
+                    "enrichment"
+                ],
+                "summary": "Удаление сохранённого ответа",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID песни",
+                        "description": "ID записи",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Запись удалена"
                     },
-                    {
-                        "description": "Обновленные данные песни",
-                        "name": "song",
-                        "in": "body",
-                        "required": true,
+                    "400": {
+                        "description": "Неверный ID",
                         "schema": {
-                            "$ref": "#/definitions/entities.Song"
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Запись не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
                         }
                     }
+                }
+            }
+        },
+        "/admin/enrichment/jobs/failed": {
+            "get": {
+                "description": "Возвращает задачи обогащения, у которых исчерпаны все попытки, начиная с последней.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "enrichment"
+                ],
+                "summary": "Задачи обогащения, завершившиеся ошибкой",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Лимит записей (по умолчанию 11, не больше 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Сдвиг записей",
+                        "name": "offset",
+                        "in": "query"
+                    }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Список задач",
                         "schema": {
-                            "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entities.EnrichmentJob"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Неверный ID или Bad Request",
+                        "description": "Неверные параметры пагинации",
                         "schema": {
                             "$ref": "#/definitions/entities.ErrorResponse"
                         }
@@ -165,27 +248,32 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Удаляет песню по идентификатору.",
+            }
+        },
+        "/admin/enrichment/jobs/{id}/retry": {
+            "post": {
+                "description": "Возвращает завершившуюся ошибкой задачу в очередь с обнулённым счётчиком попыток,\nпесня снова получает статус обогащения pending.",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "songs"
+                    "enrichment"
                 ],
-                "summary": "Удаление песни",
+                "summary": "Повтор задачи обогащения",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID песни",
+                        "description": "ID задачи",
                         "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content",
+                    "202": {
+                        "description": "Задача поставлена в очередь",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/entities.EnrichmentJob"
                         }
                     },
                     "400": {
@@ -194,6 +282,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/entities.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Задача не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Задача не завершилась ошибкой или у песни уже есть задача в очереди",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -203,52 +303,1785 @@ const docTemplate = `{
                 }
             }
         },
-        "/songs/{id}/text": {
+        "/admin/enrichment/upstream": {
             "get": {
-                "description": "Возвращает текст песни, разделенный на куплеты с пагинацией. Параметры versePage и versePageSize управляют выводом куплетов.",
+                "description": "Возвращает для каждого источника обогащения состояние автоматического выключателя\n(closed, open или half_open), время следующей пробной попытки и счётчики запросов,\nошибок и отклонённых вызовов.",
                 "produces": [
-                    "text/plain"
+                    "application/json"
                 ],
                 "tags": [
-                    "songs"
+                    "enrichment"
                 ],
-                "summary": "Получение текста песни с пагинацией куплетов",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "ID песни",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                "summary": "Состояние источников обогащения",
+                "responses": {
+                    "200": {
+                        "description": "Состояние источников обогащения",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entities.UpstreamStatus"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/trash/purge": {
+            "post": {
+                "description": "Окончательно удаляет песни, которые находятся в корзине дольше срока хранения (TRASH_RETENTION).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Очистка корзины",
+                "responses": {
+                    "200": {
+                        "description": "Количество удалённых песен",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "integer",
+                                "format": "int64"
+                            }
+                        }
                     },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/artists": {
+            "get": {
+                "description": "Получает список исполнителей с пагинацией.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "artists"
+                ],
+                "summary": "Получение списка исполнителей",
+                "parameters": [
                     {
                         "type": "integer",
-                        "description": "Номер страницы куплетов (по умолчанию 1)",
-                        "name": "versePage",
+                        "description": "Лимит записей (по умолчанию 11, не больше 1000)",
+                        "name": "limit",
                         "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "Количество куплетов на странице (по умолчанию 5)",
-                        "name": "versePageSize",
+                        "description": "Сдвиг записей",
+                        "name": "offset",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Текст песни",
+                        "description": "Список исполнителей",
                         "schema": {
-                            "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entities.Artist"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Неверный ID",
+                        "description": "Неверные параметры пагинации",
                         "schema": {
                             "$ref": "#/definitions/entities.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Песня не найдена",
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Создает нового исполнителя.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "artists"
+                ],
+                "summary": "Добавление исполнителя",
+                "parameters": [
+                    {
+                        "description": "Данные исполнителя",
+                        "name": "artist",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entities.Artist"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Созданный исполнитель",
+                        "schema": {
+                            "$ref": "#/definitions/entities.Artist"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Исполнитель уже существует",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Тело запроса слишком большое",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Название не прошло проверку, список в details",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/artists/{id}": {
+            "get": {
+                "description": "Возвращает исполнителя по идентификатору.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "artists"
+                ],
+                "summary": "Получение исполнителя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID исполнителя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Исполнитель",
+                        "schema": {
+                            "$ref": "#/definitions/entities.Artist"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный ID",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Исполнитель не найден",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Переименовывает исполнителя по идентификатору. Все его песни получают новое название группы.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "artists"
+                ],
+                "summary": "Обновление исполнителя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID исполнителя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Обновленные данные исполнителя",
+                        "name": "artist",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entities.Artist"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный ID или Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Исполнитель не найден",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Исполнитель с таким названием уже существует",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Тело запроса слишком большое",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Название не прошло проверку, список в details",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет исполнителя по идентификатору. Исполнителя, у которого есть песни, удалить нельзя.",
+                "tags": [
+                    "artists"
+                ],
+                "summary": "Удаление исполнителя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID исполнителя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный ID",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Исполнитель не найден",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "У исполнителя есть песни",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/artists/{id}/songs": {
+            "get": {
+                "description": "Возвращает песни исполнителя с пагинацией.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "artists"
+                ],
+                "summary": "Получение песен исполнителя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID исполнителя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Лимит записей (по умолчанию 11, не больше 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Сдвиг записей",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список песен",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entities.Song"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный ID или параметры пагинации",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Исполнитель не найден",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs": {
+            "get": {
+                "description": "Получает список песен с возможностью фильтрации по группе и названию, а также с пагинацией.\nПараметр match задаёт способ сравнения для всех фильтров, group_match и song_title_match переопределяют его для отдельного фильтра.\nБез параметра sort песни упорядочены по id, а при нечётком сравнении (fuzzy) по убыванию коэффициента сходства similarity.\nЕсли передан параметр cursor (пустой для первой страницы), используется keyset-пагинация: ответ имеет вид {items, next_cursor}, offset игнорируется.\nБез cursor работает прежний режим limit/offset с ответом в виде массива.\nС параметром envelope=true ответ имеет вид {items, total, limit, offset, next, prev}.\nСсылки на соседние страницы также передаются в заголовке Link (RFC 8288).\nФильтры released_after и released_before включают границы и принимают дату в любом известном формате, в том числе неполную (2006, 2006-07).\nПроизвольные условия задаются параметрами filter[поле][операция]=значение, например filter[title][contains]=love или filter[id][in]=1,2,3.\nПоля: id, artist_id, group, title, release_date, release_year, text, link. Операции: eq (по умолчанию), ne, in, gt, gte, lt, lte, is_null, not_null, prefix, contains, fuzzy.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Получение списка песен",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Название группы",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название песни",
+                        "name": "song_title",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "exact",
+                            "prefix",
+                            "contains",
+                            "fuzzy"
+                        ],
+                        "type": "string",
+                        "description": "Способ сравнения фильтров (по умолчанию exact)",
+                        "name": "match",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "exact",
+                            "prefix",
+                            "contains",
+                            "fuzzy"
+                        ],
+                        "type": "string",
+                        "description": "Способ сравнения для фильтра group",
+                        "name": "group_match",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "exact",
+                            "prefix",
+                            "contains",
+                            "fuzzy"
+                        ],
+                        "type": "string",
+                        "description": "Способ сравнения для фильтра song_title",
+                        "name": "song_title_match",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата выпуска не раньше",
+                        "name": "released_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата выпуска не позже",
+                        "name": "released_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Год выпуска",
+                        "name": "year",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Сортировка через запятую по полям id, group, title, release_date, минус означает убывание (например group,-release_date,title). Песни без даты идут в конце",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Лимит записей (по умолчанию 11, не больше 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Сдвиг записей",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор страницы из next_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Вернуть ответ в виде объекта с общим количеством и ссылками",
+                        "name": "envelope",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список песен",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entities.Song"
+                            }
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "Ссылки first, prev, next, last"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неизвестное поле или неверный фильтр, сортировка, курсор или пагинация",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Курсор не соответствует сортировке",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Сохраняет новую песню вместе с переданными releaseDate, text и link. Если какое-то из этих полей не заполнено,\nпесня получает статус обогащения pending и ставится в очередь, недостающие данные заполняет внешний API.\nХод обогащения показывает GET /songs/{id}/enrichment.\nГруппа и название сравниваются без учёта регистра и пробелов по краям. Если такая песня уже есть, возвращается 409 с её ID,\nа с параметром onConflict=update существующая песня ставится в очередь на обновление данными из внешнего API и возвращается со статусом 200.\nНеизвестные поля запрещены. Все нарушения правил полей (обязательность, длина, формат ссылки и даты) возвращаются сразу в details.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Добавление новой песни",
+                "parameters": [
+                    {
+                        "description": "Данные новой песни",
+                        "name": "song",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entities.Song"
+                        }
+                    },
+                    {
+                        "enum": [
+                            "error",
+                            "update"
+                        ],
+                        "type": "string",
+                        "description": "Действие, если песня уже существует (по умолчанию error)",
+                        "name": "onConflict",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Существующая песня, поставленная в очередь обогащения",
+                        "schema": {
+                            "$ref": "#/definitions/entities.Song"
+                        }
+                    },
+                    "201": {
+                        "description": "Созданная песня",
+                        "schema": {
+                            "$ref": "#/definitions/entities.Song"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "Адрес созданной песни"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Песня уже существует, её ID в поле existingId",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Тело запроса слишком большое",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Поля песни не прошли проверку, список в details",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/duplicates": {
+            "get": {
+                "description": "Находит пары песен, у которых похожи группа и название (например, отличаются опечаткой или знаками препинания).\nСходство считается по триграммам от 0 до 1. Порогу threshold должны соответствовать и группы, и названия,\nсходство пары - среднее этих двух значений, пары упорядочены по его убыванию.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Отчёт о похожих песнях",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "description": "Минимальное сходство групп и названий от 0 до 1 (по умолчанию 0.5)",
+                        "name": "threshold",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Лимит записей (по умолчанию 11, не больше 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Сдвиг записей",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Пары похожих песен",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entities.SongDuplicate"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный порог или параметры пагинации",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/export": {
+            "get": {
+                "description": "Выгружает все песни, подходящие под фильтры, в формате CSV, NDJSON или JSON.\nПесни читаются из курсора DB и передаются клиенту по мере чтения, без загрузки всей библиотеки в память.\nПоддерживаются те же фильтры и сортировка, что и у списка песен (GET /songs). CSV выгрузку можно загрузить обратно через POST /songs/import.",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Выгрузка библиотеки песен",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson",
+                            "json"
+                        ],
+                        "type": "string",
+                        "description": "Формат выгрузки (по умолчанию json)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Не выгружать тексты песен",
+                        "name": "omit_text",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название группы",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название песни",
+                        "name": "song_title",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "exact",
+                            "prefix",
+                            "contains",
+                            "fuzzy"
+                        ],
+                        "type": "string",
+                        "description": "Способ сравнения фильтров (по умолчанию exact)",
+                        "name": "match",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата выпуска не раньше",
+                        "name": "released_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата выпуска не позже",
+                        "name": "released_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Год выпуска",
+                        "name": "year",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Сортировка через запятую по полям id, group, title, release_date",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Песни",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entities.Song"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат, фильтр или сортировка",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/import": {
+            "post": {
+                "description": "Импортирует песни из файла CSV (с заголовком group,song[,releaseDate,text,link]) или NDJSON (по объекту с полями group, song, releaseDate, text, link на строку, строка с другими полями попадает в отчёт как failed).\nФайл передаётся телом запроса или полем file формы multipart/form-data, формат определяется по Content-Type, расширению файла или параметру format.\nПесни с незаполненными releaseDate, text или link сохраняются со статусом обогащения pending, недостающие данные заполняет очередь обогащения.\nПесни, которые уже есть в библиотеке или повторяются в файле, пропускаются. В режиме dry_run файл только проверяется и в DB ничего не записывается.",
+                "consumes": [
+                    "text/csv",
+                    "application/x-ndjson",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Массовый импорт песен",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Формат файла",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Только проверить файл",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "type": "file",
+                        "description": "Файл импорта",
+                        "name": "file",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Отчёт об импорте",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ImportReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный файл",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Файл слишком большой",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/search": {
+            "get": {
+                "description": "Ищет песни по названию, группе и тексту. Результаты упорядочены по релевантности, содержат фрагмент текста с подсветкой и номера совпавших куплетов.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Полнотекстовый поиск песен",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Поисковый запрос",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Лимит записей (по умолчанию 11, не больше 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Сдвиг записей",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Найденные песни",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entities.SongSearchResult"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Пустой запрос или неверные параметры пагинации",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}": {
+            "get": {
+                "description": "Возвращает песню целиком. Параметр include через запятую встраивает связанные данные:\nverses - количество куплетов, revisions - количество ревизий, artist - исполнитель с количеством его песен.\nHEAD возвращает те же заголовки (ETag, Content-Length) без тела.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Получение песни",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Связанные данные через запятую: verses, revisions, artist",
+                        "name": "include",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Песня",
+                        "schema": {
+                            "$ref": "#/definitions/entities.SongDetail"
+                        },
+                        "headers": {
+                            "Content-Length": {
+                                "type": "integer",
+                                "description": "Длина тела ответа, в том числе для HEAD"
+                            },
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия песни"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный ID или include",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Песня не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Обновляет данные песни по идентификатору. Передаётся JSON объект песни.\nЕсли передан заголовок If-Match, песня обновляется только при совпадении версии.\nНеизвестные поля запрещены. Все нарушения правил полей (обязательность, длина, формат ссылки и даты) возвращаются сразу в details.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Обновление данных песни",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag песни, полученный при чтении",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Обновленные данные песни",
+                        "name": "song",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entities.Song"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный ID, If-Match или Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Песня не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Песня с такими группой и названием уже существует",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Версия песни изменилась",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Тело запроса слишком большое",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Поля песни не прошли проверку, список в details",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Перемещает песню в корзину. Песню можно восстановить, пока она не удалена окончательно очисткой корзины.\nЕсли передан заголовок If-Match, песня удаляется только при совпадении версии.",
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Удаление песни",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag песни, полученный при чтении",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный ID или If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Песня не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Версия песни изменилась",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "head": {
+                "description": "Возвращает песню целиком. Параметр include через запятую встраивает связанные данные:\nverses - количество куплетов, revisions - количество ревизий, artist - исполнитель с количеством его песен.\nHEAD возвращает те же заголовки (ETag, Content-Length) без тела.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Получение песни",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Связанные данные через запятую: verses, revisions, artist",
+                        "name": "include",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Песня",
+                        "schema": {
+                            "$ref": "#/definitions/entities.SongDetail"
+                        },
+                        "headers": {
+                            "Content-Length": {
+                                "type": "integer",
+                                "description": "Длина тела ответа, в том числе для HEAD"
+                            },
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия песни"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный ID или include",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Песня не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Изменяет только переданные поля песни. Принимает JSON Merge Patch (application/merge-patch+json или application/json)\nи JSON Patch (application/json-patch+json) с операциями add, replace, remove и test.\nnull в Merge Patch и remove в JSON Patch очищают необязательные поля, group и song очистить нельзя.",
+                "consumes": [
+                    "application/merge-patch+json",
+                    "application/json-patch+json",
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Частичное обновление песни",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag песни, полученный при чтении",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Изменяемые поля песни",
+                        "name": "patch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entities.SongPatch"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Обновленная песня",
+                        "schema": {
+                            "$ref": "#/definitions/entities.Song"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Новая версия песни"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный ID, If-Match или Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Песня не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Песня с такими группой и названием уже существует",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Версия песни изменилась",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Тело запроса слишком большое",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Неподдерживаемый формат патча",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Поля песни не прошли проверку, список в details",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/enrichment": {
+            "get": {
+                "description": "Возвращает статус обогащения песни данными внешнего API (pending, done или failed)\nи её последнюю задачу обогащения с числом попыток и последней ошибкой.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "enrichment"
+                ],
+                "summary": "Статус обогащения песни",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Статус обогащения",
+                        "schema": {
+                            "$ref": "#/definitions/entities.SongEnrichment"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный ID",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Песня не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/merge": {
+            "post": {
+                "description": "Объединяет песню sourceId с песней из пути запроса: дата выпуска, текст и ссылка sourceId заполняют пустые поля песни,\nа сама sourceId перемещается в корзину. Если передан заголовок If-Match, проверяется версия песни из пути запроса.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Объединение песен",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID песни, которая остаётся в библиотеке",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag песни, полученный при чтении",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Песня, которая объединяется",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entities.SongMergeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Объединённая песня",
+                        "schema": {
+                            "$ref": "#/definitions/entities.Song"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный ID, If-Match или Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Песня не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Версия песни изменилась",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Тело запроса слишком большое",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/restore": {
+            "post": {
+                "description": "Возвращает песню из корзины в общий список.",
+                "tags": [
+                    "trash"
+                ],
+                "summary": "Восстановление песни из корзины",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный ID",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Песня не найдена в корзине",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "В библиотеке уже есть такая песня",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/revisions": {
+            "get": {
+                "description": "Возвращает ревизии песни, начиная с последней.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "revisions"
+                ],
+                "summary": "Получение истории изменений песни",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Лимит записей (по умолчанию 11, не больше 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Сдвиг записей",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список ревизий",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entities.SongRevision"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный ID или параметры пагинации",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/revisions/diff": {
+            "get": {
+                "description": "Возвращает построчный diff поля text между ревизиями from и to.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "revisions"
+                ],
+                "summary": "Сравнение текста песни в двух ревизиях",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер исходной ревизии",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер итоговой ревизии",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Diff текста",
+                        "schema": {
+                            "$ref": "#/definitions/entities.SongRevisionDiff"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверные параметры",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Ревизия не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Текст ревизии слишком длинный для сравнения",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/revisions/{rev}": {
+            "get": {
+                "description": "Возвращает снимок данных песни в указанной ревизии.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "revisions"
+                ],
+                "summary": "Получение ревизии песни",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер ревизии",
+                        "name": "rev",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ревизия",
+                        "schema": {
+                            "$ref": "#/definitions/entities.SongRevision"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный ID или номер ревизии",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Ревизия не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/revisions/{rev}/revert": {
+            "post": {
+                "description": "Записывает в песню данные из указанной ревизии. Откат сохраняется как новая ревизия.\nЕсли передан If-Match, откат выполняется только для этой версии песни.",
+                "tags": [
+                    "revisions"
+                ],
+                "summary": "Откат песни к ревизии",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер ревизии",
+                        "name": "rev",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag песни, полученный при чтении",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный ID, номер ревизии или If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Ревизия не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "В библиотеке уже есть песня с такими группой и названием",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Версия песни изменилась",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/text": {
+            "get": {
+                "description": "Возвращает текст песни, разделенный на куплеты с пагинацией. Параметры versePage и versePageSize управляют выводом куплетов.",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Получение текста песни с пагинацией куплетов",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер страницы куплетов (по умолчанию 1)",
+                        "name": "versePage",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество куплетов на странице (по умолчанию 5)",
+                        "name": "versePageSize",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Текст песни",
+                        "schema": {
+                            "type": "string"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия песни"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный ID",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Песня не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Неверная страница куплетов",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/trash/songs": {
+            "get": {
+                "description": "Возвращает удалённые песни, начиная с удалённых последними.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trash"
+                ],
+                "summary": "Получение песен из корзины",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Лимит записей (по умолчанию 11, не больше 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Сдвиг записей",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список песен в корзине",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entities.Song"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверные параметры пагинации",
                         "schema": {
                             "$ref": "#/definitions/entities.ErrorResponse"
                         }
@@ -264,24 +2097,490 @@ const docTemplate = `{
         }
     },
     "definitions": {
+        "entities.Artist": {
+            "description": "Структура для представления исполнителя, к которому относятся песни.",
+            "type": "object",
+            "properties": {
+                "id": {
+                    "description": "ID уникальный идентификатор исполнителя.\n\nrequired: true\n\nexample: 1",
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "Name название группы или исполнителя.\n\nrequired: true\n\nexample: \"The Beatles\"",
+                    "type": "string"
+                }
+            }
+        },
+        "entities.ArtistSummary": {
+            "description": "Исполнитель и количество его песен в библиотеке (без корзины).",
+            "type": "object",
+            "properties": {
+                "id": {
+                    "description": "ID уникальный идентификатор исполнителя.\n\nrequired: true\n\nexample: 1",
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "Name название группы или исполнителя.\n\nrequired: true\n\nexample: \"The Beatles\"",
+                    "type": "string"
+                },
+                "songCount": {
+                    "description": "SongCount количество песен исполнителя.\n\nexample: 24",
+                    "type": "integer"
+                }
+            }
+        },
+        "entities.BreakerState": {
+            "type": "string",
+            "enum": [
+                "closed",
+                "open",
+                "half_open"
+            ],
+            "x-enum-varnames": [
+                "BreakerClosed",
+                "BreakerOpen",
+                "BreakerHalfOpen"
+            ]
+        },
+        "entities.DatePrecision": {
+            "type": "string",
+            "enum": [
+                "year",
+                "month",
+                "day"
+            ],
+            "x-enum-varnames": [
+                "PrecisionYear",
+                "PrecisionMonth",
+                "PrecisionDay"
+            ]
+        },
+        "entities.DiffLine": {
+            "description": "Строка diff: op принимает значения equal, insert или delete.",
+            "type": "object",
+            "properties": {
+                "newLine": {
+                    "description": "NewLine номер строки в новом тексте (с 1), 0 для удалённых строк.\n\nexample: 4",
+                    "type": "integer"
+                },
+                "oldLine": {
+                    "description": "OldLine номер строки в исходном тексте (с 1), 0 для добавленных строк.\n\nexample: 0",
+                    "type": "integer"
+                },
+                "op": {
+                    "description": "Op тип изменения строки: equal, insert или delete.\n\nexample: \"insert\"",
+                    "type": "string"
+                },
+                "text": {
+                    "description": "Text содержимое строки.\n\nexample: \"Hey, Jude, don't be afraid\"",
+                    "type": "string"
+                }
+            }
+        },
+        "entities.EnrichmentCacheEntry": {
+            "description": "Ответ источников обогащения для группы и названия песни и срок его хранения.",
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "description": "CreatedAt время сохранения ответа.\n\nexample: \"2025-04-07T10:00:00Z\"",
+                    "type": "string"
+                },
+                "expired": {
+                    "description": "Expired срок хранения записи истёк, при следующем запросе она будет заменена.\n\nexample: false",
+                    "type": "boolean"
+                },
+                "expiresAt": {
+                    "description": "ExpiresAt время, после которого ответ запрашивается заново.\n\nexample: \"2025-04-08T10:00:00Z\"",
+                    "type": "string"
+                },
+                "found": {
+                    "description": "Found источники знают песню. Для false запоминается ответ 404 и поля данных пусты.\n\nexample: true",
+                    "type": "boolean"
+                },
+                "group": {
+                    "description": "Group название группы, по которому был запрос.\n\nexample: \"The Beatles\"",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID идентификатор записи.\n\nexample: 3",
+                    "type": "integer"
+                },
+                "info": {
+                    "description": "Info данные песни от источников.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entities.ExternalSongInfo"
+                        }
+                    ]
+                },
+                "song": {
+                    "description": "Title название песни, по которому был запрос.\n\nexample: \"Hey Jude\"",
+                    "type": "string"
+                }
+            }
+        },
+        "entities.EnrichmentJob": {
+            "description": "Задача обогащения песни: состояние, число попыток и последняя ошибка.",
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "description": "Attempts количество выполненных попыток.\n\nexample: 3",
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "description": "CreatedAt время постановки задачи в очередь.\n\nexample: \"2025-04-05T10:00:00Z\"",
+                    "type": "string"
+                },
+                "group": {
+                    "description": "Group название группы песни.\n\nexample: \"The Beatles\"",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID идентификатор задачи.\n\nexample: 7",
+                    "type": "integer"
+                },
+                "lastError": {
+                    "description": "LastError ошибка последней неудачной попытки.\n\nexample: \"ошибка внешнего API: внешнее API вернуло статус 503\"",
+                    "type": "string"
+                },
+                "overwrite": {
+                    "description": "Overwrite данные внешнего API заменяют уже заполненные поля песни.\n\nexample: false",
+                    "type": "boolean"
+                },
+                "runAt": {
+                    "description": "RunAt время, не раньше которого задача будет выполнена.\n\nexample: \"2025-04-05T10:00:30Z\"",
+                    "type": "string"
+                },
+                "song": {
+                    "description": "Title название песни.\n\nexample: \"Hey Jude\"",
+                    "type": "string"
+                },
+                "songId": {
+                    "description": "SongID идентификатор песни.\n\nexample: 1",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "Status состояние задачи: pending, running, done или failed.\n\nexample: \"failed\"",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entities.JobStatus"
+                        }
+                    ]
+                },
+                "updatedAt": {
+                    "description": "UpdatedAt время последнего изменения задачи.\n\nexample: \"2025-04-05T10:00:31Z\"",
+                    "type": "string"
+                }
+            }
+        },
+        "entities.EnrichmentStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "done",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "EnrichmentPending",
+                "EnrichmentDone",
+                "EnrichmentFailed"
+            ]
+        },
+        "entities.ErrorCode": {
+            "type": "string",
+            "enum": [
+                "invalid_id",
+                "invalid_body",
+                "invalid_parameter",
+                "invalid_filter",
+                "invalid_sort",
+                "invalid_cursor",
+                "invalid_file",
+                "invalid_if_match",
+                "payload_too_large",
+                "unsupported_media_type",
+                "patch_test_failed",
+                "not_found",
+                "route_not_found",
+                "method_not_allowed",
+                "conflict",
+                "duplicate_song",
+                "version_mismatch",
+                "validation_failed",
+                "upstream_error",
+                "internal_error"
+            ],
+            "x-enum-varnames": [
+                "CodeInvalidID",
+                "CodeInvalidBody",
+                "CodeInvalidParameter",
+                "CodeInvalidFilter",
+                "CodeInvalidSort",
+                "CodeInvalidCursor",
+                "CodeInvalidFile",
+                "CodeInvalidIfMatch",
+                "CodePayloadTooLarge",
+                "CodeUnsupportedMediaType",
+                "CodePatchTestFailed",
+                "CodeNotFound",
+                "CodeRouteNotFound",
+                "CodeMethodNotAllowed",
+                "CodeConflict",
+                "CodeDuplicateSong",
+                "CodeVersionMismatch",
+                "CodeValidationFailed",
+                "CodeUpstreamError",
+                "CodeInternalError"
+            ]
+        },
         "entities.ErrorResponse": {
-            "description": "Структура для представления ошибки, которая включает код ошибки и сообщение.",
+            "description": "Ошибка в формате application/problem+json (RFC 7807) с машиночитаемым кодом и ID запроса.",
             "type": "object",
             "properties": {
                 "code": {
-                    "description": "Код ошибки\n@example 500",
+                    "description": "Машиночитаемый код ошибки\n@example not_found",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entities.ErrorCode"
+                        }
+                    ]
+                },
+                "detail": {
+                    "description": "Сообщение ошибки\n@example песня 42: не найдено",
+                    "type": "string"
+                },
+                "details": {
+                    "description": "Ошибки отдельных полей запроса",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.FieldError"
+                    }
+                },
+                "existingId": {
+                    "description": "ID уже существующей песни для ошибки duplicate_song\n@example 42",
+                    "type": "integer"
+                },
+                "instance": {
+                    "description": "Путь запроса, вызвавшего ошибку\n@example /songs/42",
+                    "type": "string"
+                },
+                "requestId": {
+                    "description": "ID запроса, он же передаётся в заголовке X-Request-ID\n@example 4f1c2a7e9b3d5f60",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Код ответа HTTP\n@example 404",
                     "type": "integer"
                 },
+                "title": {
+                    "description": "Краткое описание типа ошибки\n@example Not Found",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "Тип ошибки, URI вида urn:problem:\u003ccode\u003e\n@example urn:problem:not_found",
+                    "type": "string"
+                }
+            }
+        },
+        "entities.ExternalSongInfo": {
+            "type": "object",
+            "properties": {
+                "link": {
+                    "description": "Link ссылка на дополнительную информацию о песне.\n\nrequired: true\nexample: https://example.com/song-info",
+                    "type": "string"
+                },
+                "releaseDate": {
+                    "description": "ReleaseDate дата выпуска песни в формате YYYY-MM-DD.\n\nrequired: true\nexample: 2023-01-01",
+                    "type": "string"
+                },
+                "text": {
+                    "description": "Text текст песни.\n\nrequired: true",
+                    "type": "string"
+                }
+            }
+        },
+        "entities.FieldError": {
+            "description": "Поле запроса, не прошедшее проверку, и причина ошибки.",
+            "type": "object",
+            "properties": {
+                "field": {
+                    "description": "Имя поля или параметра запроса\n@example threshold",
+                    "type": "string"
+                },
                 "message": {
-                    "description": "Сообщение ошибки\n@example \"Internal Server Error\"",
+                    "description": "Причина ошибки\n@example ожидается число от 0 до 1",
+                    "type": "string"
+                }
+            }
+        },
+        "entities.ImportReport": {
+            "description": "Итоги импорта и результат по каждой строке в порядке следования в файле.",
+            "type": "object",
+            "properties": {
+                "created": {
+                    "description": "Created количество созданных (в dry-run - подлежащих созданию) песен.\n\nexample: 1",
+                    "type": "integer"
+                },
+                "dryRun": {
+                    "description": "DryRun импорт выполнен без записи в DB.\n\nexample: false",
+                    "type": "boolean"
+                },
+                "failed": {
+                    "description": "Failed количество строк с ошибками.\n\nexample: 1",
+                    "type": "integer"
+                },
+                "rows": {
+                    "description": "Rows результаты по строкам.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.ImportRowResult"
+                    }
+                },
+                "skipped": {
+                    "description": "Skipped количество пропущенных дубликатов.\n\nexample: 1",
+                    "type": "integer"
+                },
+                "total": {
+                    "description": "Total количество строк в файле.\n\nexample: 3",
+                    "type": "integer"
+                }
+            }
+        },
+        "entities.ImportRowResult": {
+            "description": "Результат обработки строки файла: status принимает значения created, would_create (dry-run), skipped_duplicate или failed.",
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Error причина ошибки для строк со статусом failed.\n\nexample: \"неверные данные: song: обязательное поле\"",
+                    "type": "string"
+                },
+                "group": {
+                    "description": "Group название группы.\n\nexample: \"Muse\"",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID идентификатор созданной или уже существующей песни.\n\nexample: 42",
+                    "type": "integer"
+                },
+                "line": {
+                    "description": "Line номер строки в файле.\n\nexample: 2",
+                    "type": "integer"
+                },
+                "song": {
+                    "description": "Title название песни.\n\nexample: \"Supermassive Black Hole\"",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status результат обработки строки.\n\nexample: \"created\"",
                     "type": "string"
                 }
             }
         },
+        "entities.JobStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "running",
+                "done",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "JobPending",
+                "JobRunning",
+                "JobDone",
+                "JobFailed"
+            ]
+        },
         "entities.Song": {
-            "description": "Структура для представления песни, которая включает 6 полей.",
+            "description": "Структура для представления песни, которая включает 12 полей.",
+            "type": "object",
+            "properties": {
+                "artistId": {
+                    "description": "ArtistID идентификатор исполнителя из таблицы artists.\n\nexample: 1",
+                    "type": "integer"
+                },
+                "deletedAt": {
+                    "description": "DeletedAt время перемещения песни в корзину, заполнено только у песен из корзины.\n\nexample: \"2025-03-25T10:00:00Z\"",
+                    "type": "string"
+                },
+                "enrichmentStatus": {
+                    "description": "EnrichmentStatus статус обогащения данными внешнего API: pending, done или failed.\n\nexample: \"done\"",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entities.EnrichmentStatus"
+                        }
+                    ]
+                },
+                "group": {
+                    "description": "Group название группы или исполнителя.\n\nrequired: true\n\nexample: \"The Beatles\"",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID уникальный идентификатор песни.\n\nrequired: true\n\nexample: 1",
+                    "type": "integer"
+                },
+                "link": {
+                    "description": "Link ссылка на дополнительную информацию о песне.\n\nexample: \"https://example.com/song-info\"",
+                    "type": "string"
+                },
+                "releaseDate": {
+                    "description": "ReleaseDate дата выпуска песни в формате ISO 8601 с учётом точности: YYYY, YYYY-MM или YYYY-MM-DD.\nПри записи принимаются также форматы внешних API, например DD.MM.YYYY.\n\nexample: \"2023-01-01\"",
+                    "type": "string"
+                },
+                "releaseDatePrecision": {
+                    "description": "ReleaseDatePrecision точность даты выпуска: year, month или day.\n\nexample: \"day\"",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entities.DatePrecision"
+                        }
+                    ]
+                },
+                "similarity": {
+                    "description": "Similarity коэффициент сходства с фильтром при нечётком поиске (от 0 до 1).\n\nexample: 0.63",
+                    "type": "number"
+                },
+                "song": {
+                    "description": "Title название песни.\n\nrequired: true\n\nexample: \"Hey Jude\"",
+                    "type": "string"
+                },
+                "text": {
+                    "description": "Text текст песни.\n\nexample: \"Hey, Jude, don't make it bad...\"",
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Version номер версии записи, увеличивается при каждом изменении. Передаётся в ETag.\n\nexample: 3",
+                    "type": "integer"
+                }
+            }
+        },
+        "entities.SongDetail": {
+            "description": "Песня целиком и связанные данные, запрошенные параметром include.",
             "type": "object",
             "properties": {
+                "artist": {
+                    "description": "Artist исполнитель песни, заполняется при include=artist.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entities.ArtistSummary"
+                        }
+                    ]
+                },
+                "artistId": {
+                    "description": "ArtistID идентификатор исполнителя из таблицы artists.\n\nexample: 1",
+                    "type": "integer"
+                },
+                "deletedAt": {
+                    "description": "DeletedAt время перемещения песни в корзину, заполнено только у песен из корзины.\n\nexample: \"2025-03-25T10:00:00Z\"",
+                    "type": "string"
+                },
+                "enrichmentStatus": {
+                    "description": "EnrichmentStatus статус обогащения данными внешнего API: pending, done или failed.\n\nexample: \"done\"",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entities.EnrichmentStatus"
+                        }
+                    ]
+                },
                 "group": {
                     "description": "Group название группы или исполнителя.\n\nrequired: true\n\nexample: \"The Beatles\"",
                     "type": "string"
@@ -295,9 +2594,25 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "releaseDate": {
-                    "description": "ReleaseDate дата выпуска песни в формате YYYY-MM-DD.\n\nexample: \"2023-01-01\"",
+                    "description": "ReleaseDate дата выпуска песни в формате ISO 8601 с учётом точности: YYYY, YYYY-MM или YYYY-MM-DD.\nПри записи принимаются также форматы внешних API, например DD.MM.YYYY.\n\nexample: \"2023-01-01\"",
                     "type": "string"
                 },
+                "releaseDatePrecision": {
+                    "description": "ReleaseDatePrecision точность даты выпуска: year, month или day.\n\nexample: \"day\"",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entities.DatePrecision"
+                        }
+                    ]
+                },
+                "revisionCount": {
+                    "description": "RevisionCount количество ревизий песни, заполняется при include=revisions.\n\nexample: 3",
+                    "type": "integer"
+                },
+                "similarity": {
+                    "description": "Similarity коэффициент сходства с фильтром при нечётком поиске (от 0 до 1).\n\nexample: 0.63",
+                    "type": "number"
+                },
                 "song": {
                     "description": "Title название песни.\n\nrequired: true\n\nexample: \"Hey Jude\"",
                     "type": "string"
@@ -305,6 +2620,244 @@ const docTemplate = `{
                 "text": {
                     "description": "Text текст песни.\n\nexample: \"Hey, Jude, don't make it bad...\"",
                     "type": "string"
+                },
+                "verseCount": {
+                    "description": "VerseCount количество куплетов (строк) текста, заполняется при include=verses.\n\nexample: 12",
+                    "type": "integer"
+                },
+                "version": {
+                    "description": "Version номер версии записи, увеличивается при каждом изменении. Передаётся в ETag.\n\nexample: 3",
+                    "type": "integer"
+                }
+            }
+        },
+        "entities.SongDuplicate": {
+            "description": "Две песни, у которых похожи группа и название. Песню duplicate можно объединить с song через POST /songs/{id}/merge.",
+            "type": "object",
+            "properties": {
+                "duplicate": {
+                    "description": "Похожая на неё песня",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entities.Song"
+                        }
+                    ]
+                },
+                "similarity": {
+                    "description": "Среднее сходства групп и названий от 0 до 1\n@example 0.87",
+                    "type": "number"
+                },
+                "song": {
+                    "description": "Песня с меньшим ID",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entities.Song"
+                        }
+                    ]
+                }
+            }
+        },
+        "entities.SongEnrichment": {
+            "description": "Статус обогащения песни и её последняя задача обогащения.",
+            "type": "object",
+            "properties": {
+                "job": {
+                    "description": "Job последняя задача обогащения, отсутствует у песен, обогащённых до появления очереди.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entities.EnrichmentJob"
+                        }
+                    ]
+                },
+                "songId": {
+                    "description": "SongID идентификатор песни.\n\nexample: 1",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "Status статус обогащения: pending, done или failed.\n\nexample: \"pending\"",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entities.EnrichmentStatus"
+                        }
+                    ]
+                }
+            }
+        },
+        "entities.SongMergeRequest": {
+            "description": "Песня sourceId объединяется с песней из пути запроса: её данные дополняют пустые поля, а сама она перемещается в корзину.",
+            "type": "object",
+            "properties": {
+                "sourceId": {
+                    "description": "ID песни, которая объединяется с песней из пути запроса\n@example 42",
+                    "type": "integer"
+                }
+            }
+        },
+        "entities.SongPatch": {
+            "description": "Тело PATCH /songs/{id} в формате JSON Merge Patch. Передаются только изменяемые поля.",
+            "type": "object",
+            "properties": {
+                "group": {
+                    "description": "Group новое название группы или исполнителя.\n\nexample: \"The Beatles\"",
+                    "type": "string"
+                },
+                "link": {
+                    "description": "Link новая ссылка на дополнительную информацию о песне.\n\nexample: \"https://example.com/song-info\"",
+                    "type": "string"
+                },
+                "releaseDate": {
+                    "description": "ReleaseDate новая дата выпуска песни.\n\nexample: \"2023-01-01\"",
+                    "type": "string"
+                },
+                "song": {
+                    "description": "Title новое название песни.\n\nexample: \"Hey Jude\"",
+                    "type": "string"
+                },
+                "text": {
+                    "description": "Text новый текст песни.\n\nexample: \"Hey, Jude, don't make it bad...\"",
+                    "type": "string"
+                }
+            }
+        },
+        "entities.SongRevision": {
+            "description": "Ревизия песни: номер, операция, которая её создала, и снимок данных песни на тот момент.",
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "description": "CreatedAt время создания ревизии.\n\nexample: \"2025-03-27T15:00:00Z\"",
+                    "type": "string"
+                },
+                "operation": {
+                    "description": "Operation операция, создавшая ревизию: create, update, delete или restore.\n\nexample: \"update\"",
+                    "type": "string"
+                },
+                "revision": {
+                    "description": "Revision номер ревизии в пределах песни, начиная с 1.\n\nexample: 3",
+                    "type": "integer"
+                },
+                "snapshot": {
+                    "description": "Snapshot данные песни в этой ревизии.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entities.Song"
+                        }
+                    ]
+                },
+                "songId": {
+                    "description": "SongID идентификатор песни.\n\nexample: 1",
+                    "type": "integer"
+                }
+            }
+        },
+        "entities.SongRevisionDiff": {
+            "description": "Построчный diff поля text между ревизиями from и to.",
+            "type": "object",
+            "properties": {
+                "from": {
+                    "description": "From номер исходной ревизии.\n\nexample: 2",
+                    "type": "integer"
+                },
+                "lines": {
+                    "description": "Lines строки diff.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.DiffLine"
+                    }
+                },
+                "songId": {
+                    "description": "SongID идентификатор песни.\n\nexample: 1",
+                    "type": "integer"
+                },
+                "to": {
+                    "description": "To номер итоговой ревизии.\n\nexample: 3",
+                    "type": "integer"
+                }
+            }
+        },
+        "entities.SongSearchResult": {
+            "description": "Результат поиска по тексту: песня, релевантность, фрагмент текста с подсветкой и номера совпавших куплетов.",
+            "type": "object",
+            "properties": {
+                "artistId": {
+                    "description": "ArtistID идентификатор исполнителя.\n\nexample: 1",
+                    "type": "integer"
+                },
+                "group": {
+                    "description": "Group название группы или исполнителя.\n\nexample: \"The Beatles\"",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID уникальный идентификатор песни.\n\nexample: 1",
+                    "type": "integer"
+                },
+                "rank": {
+                    "description": "Rank релевантность песни запросу, чем больше, тем выше.\n\nexample: 0.6079271",
+                    "type": "number"
+                },
+                "snippet": {
+                    "description": "Snippet фрагмент текста с выделенными совпадениями.\n\nexample: \"\u003cb\u003eHey\u003c/b\u003e, \u003cb\u003eJude\u003c/b\u003e, don't make it bad\"",
+                    "type": "string"
+                },
+                "song": {
+                    "description": "Title название песни.\n\nexample: \"Hey Jude\"",
+                    "type": "string"
+                },
+                "verses": {
+                    "description": "Verses номера совпавших куплетов (с 1), совместимые с пагинацией GET /songs/{id}/text.\n\nexample: [1, 5]",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "entities.UpstreamStatus": {
+            "description": "Состояние автоматического выключателя источника обогащения и счётчики запросов.",
+            "type": "object",
+            "properties": {
+                "consecutiveFailures": {
+                    "description": "ConsecutiveFailures количество неудачных запросов подряд.\n\nexample: 5",
+                    "type": "integer"
+                },
+                "failureThreshold": {
+                    "description": "FailureThreshold количество неудач подряд, после которого выключатель открывается.\n\nexample: 5",
+                    "type": "integer"
+                },
+                "failures": {
+                    "description": "Failures количество неудачных запросов с запуска сервиса.\n\nexample: 7",
+                    "type": "integer"
+                },
+                "lastError": {
+                    "description": "LastError ошибка последнего неудачного запроса.\n\nexample: \"ошибка внешнего API: внешнее API вернуло статус 503\"",
+                    "type": "string"
+                },
+                "openedAt": {
+                    "description": "OpenedAt время последнего открытия выключателя.\n\nexample: \"2025-04-06T12:00:00Z\"",
+                    "type": "string"
+                },
+                "provider": {
+                    "description": "Provider имя источника обогащения.\n\nexample: \"lyrics\"",
+                    "type": "string"
+                },
+                "rejected": {
+                    "description": "Rejected количество вызовов, отклонённых открытым выключателем.\n\nexample: 3",
+                    "type": "integer"
+                },
+                "requests": {
+                    "description": "Requests количество запросов к внешнему API с запуска сервиса, включая повторы.\n\nexample: 120",
+                    "type": "integer"
+                },
+                "retryAt": {
+                    "description": "RetryAt время, после которого открытый выключатель пропустит пробный запрос.\n\nexample: \"2025-04-06T12:00:30Z\"",
+                    "type": "string"
+                },
+                "state": {
+                    "description": "State состояние выключателя: closed, open или half_open.\n\nexample: \"open\"",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entities.BreakerState"
+                        }
+                    ]
                 }
             }
         }
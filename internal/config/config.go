@@ -5,6 +5,42 @@ import (
 	"github.com/joho/godotenv"
 	"log/slog"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultTrashRetention срок хранения песен в корзине по умолчанию
+	defaultTrashRetention = 30 * 24 * time.Hour
+	// defaultImportConcurrency количество строк импорта, обрабатываемых параллельно, по умолчанию
+	defaultImportConcurrency = 4
+
+	// Ограничения времени операций по умолчанию
+	defaultDBTimeout          = 5 * time.Second
+	defaultExportTimeout      = 10 * time.Minute
+	defaultExternalAPITimeout = 10 * time.Second
+	defaultImportTimeout      = 5 * time.Minute
+
+	// Параметры очереди обогащения по умолчанию
+	defaultEnrichmentWorkers      = 2
+	defaultEnrichmentMaxAttempts  = 5
+	defaultEnrichmentPollInterval = 2 * time.Second
+	defaultEnrichmentRetryDelay   = 30 * time.Second
+	defaultEnrichmentJobLease     = time.Minute
+	defaultEnrichmentMode         = "priority"
+	defaultEnrichmentCacheTTL     = 24 * time.Hour
+	// defaultEnrichmentNotFoundTTL срок хранения ответа «песня не найдена» по умолчанию
+	defaultEnrichmentNotFoundTTL = time.Hour
+	// defaultEnrichmentProvider имя источника обогащения из EXTERNAL_URL, когда ENRICHMENT_PROVIDERS не задан
+	defaultEnrichmentProvider = "default"
+
+	// Параметры повторов и выключателя внешнего API по умолчанию
+	defaultExternalAPIRetries          = 2
+	defaultExternalAPIRetryBaseDelay   = 200 * time.Millisecond
+	defaultExternalAPIRetryMaxDelay    = 5 * time.Second
+	defaultExternalAPIBreakerThreshold = 5
+	defaultExternalAPIBreakerTimeout   = 30 * time.Second
 )
 
 func LoadEnv() {
@@ -31,3 +67,219 @@ func GetDBConnectionString() string {
 func GetExternalAPIURL() string {
 	return os.Getenv("EXTERNAL_URL")
 }
+
+// GetTrashRetention возвращает срок хранения песен в корзине (TRASH_RETENTION, например "720h")
+func GetTrashRetention() time.Duration {
+	const op = "internal.config.GetTrashRetention"
+
+	value := os.Getenv("TRASH_RETENTION")
+	if value == "" {
+		return defaultTrashRetention
+	}
+	retention, err := time.ParseDuration(value)
+	if err != nil || retention < 0 {
+		slog.Error(op, "Неверный срок хранения корзины, используется значение по умолчанию",
+			slog.String("value", value))
+		return defaultTrashRetention
+	}
+	return retention
+}
+
+// Timeouts ограничения времени выполнения операций
+type Timeouts struct {
+	// DB время одного запроса к DB (DB_TIMEOUT)
+	DB time.Duration
+	// Export время выгрузки всей библиотеки (EXPORT_TIMEOUT)
+	Export time.Duration
+	// ExternalAPI время одного запроса к внешнему API (EXTERNAL_API_TIMEOUT)
+	ExternalAPI time.Duration
+	// Import время импорта одного файла (IMPORT_TIMEOUT)
+	Import time.Duration
+}
+
+// GetTimeouts возвращает ограничения времени операций, например DB_TIMEOUT=3s
+func GetTimeouts() Timeouts {
+	const op = "internal.config.GetTimeouts"
+
+	return Timeouts{
+		DB:          getDuration(op, "DB_TIMEOUT", defaultDBTimeout),
+		Export:      getDuration(op, "EXPORT_TIMEOUT", defaultExportTimeout),
+		ExternalAPI: getDuration(op, "EXTERNAL_API_TIMEOUT", defaultExternalAPITimeout),
+		Import:      getDuration(op, "IMPORT_TIMEOUT", defaultImportTimeout),
+	}
+}
+
+// getDuration читает положительную длительность из переменной окружения key
+func getDuration(op, key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		slog.Error(op, "Неверная длительность, используется значение по умолчанию",
+			slog.String("key", key), slog.String("value", value))
+		return defaultValue
+	}
+	return duration
+}
+
+// GetImportConcurrency возвращает количество строк импорта, обрабатываемых параллельно (IMPORT_CONCURRENCY)
+func GetImportConcurrency() int {
+	const op = "internal.config.GetImportConcurrency"
+
+	return getInt(op, "IMPORT_CONCURRENCY", defaultImportConcurrency)
+}
+
+// Enrichment параметры очереди обогащения песен
+type Enrichment struct {
+	// Workers количество воркеров (ENRICHMENT_WORKERS)
+	Workers int
+	// MaxAttempts количество попыток обогащения одной песни (ENRICHMENT_MAX_ATTEMPTS)
+	MaxAttempts int
+	// PollInterval пауза между проверками пустой очереди (ENRICHMENT_POLL_INTERVAL)
+	PollInterval time.Duration
+	// RetryDelay пауза перед повтором, растёт с каждой попыткой (ENRICHMENT_RETRY_DELAY)
+	RetryDelay time.Duration
+	// JobLease время, после которого задачу упавшего воркера забирает другой (ENRICHMENT_JOB_LEASE)
+	JobLease time.Duration
+	// Providers источники обогащения в порядке приоритета (ENRICHMENT_PROVIDERS)
+	Providers []EnrichmentProvider
+	// Mode порядок опроса источников: priority или parallel (ENRICHMENT_MODE)
+	Mode string
+	// Merge источники для отдельных полей в порядке предпочтения (ENRICHMENT_MERGE)
+	Merge map[string][]string
+	// CacheTTL срок хранения ответа источников (ENRICHMENT_CACHE_TTL)
+	CacheTTL time.Duration
+	// CacheNotFoundTTL срок хранения ответа «песня не найдена» (ENRICHMENT_CACHE_NOT_FOUND_TTL)
+	CacheNotFoundTTL time.Duration
+}
+
+// EnrichmentProvider источник обогащения
+type EnrichmentProvider struct {
+	Name string
+	URL  string
+}
+
+// GetEnrichment возвращает параметры очереди обогащения, например ENRICHMENT_WORKERS=4
+func GetEnrichment() Enrichment {
+	const op = "internal.config.GetEnrichment"
+
+	return Enrichment{
+		Workers:          getInt(op, "ENRICHMENT_WORKERS", defaultEnrichmentWorkers),
+		MaxAttempts:      getInt(op, "ENRICHMENT_MAX_ATTEMPTS", defaultEnrichmentMaxAttempts),
+		PollInterval:     getDuration(op, "ENRICHMENT_POLL_INTERVAL", defaultEnrichmentPollInterval),
+		RetryDelay:       getDuration(op, "ENRICHMENT_RETRY_DELAY", defaultEnrichmentRetryDelay),
+		JobLease:         getDuration(op, "ENRICHMENT_JOB_LEASE", defaultEnrichmentJobLease),
+		Providers:        getEnrichmentProviders(op),
+		Mode:             getString("ENRICHMENT_MODE", defaultEnrichmentMode),
+		Merge:            getEnrichmentMerge(op),
+		CacheTTL:         getDuration(op, "ENRICHMENT_CACHE_TTL", defaultEnrichmentCacheTTL),
+		CacheNotFoundTTL: getDuration(op, "ENRICHMENT_CACHE_NOT_FOUND_TTL", defaultEnrichmentNotFoundTTL),
+	}
+}
+
+// getEnrichmentProviders читает источники обогащения из ENRICHMENT_PROVIDERS
+// в виде "lyrics=http://lyrics/info,links=http://links/info". Без переменной используется EXTERNAL_URL.
+func getEnrichmentProviders(op string) []EnrichmentProvider {
+	value := os.Getenv("ENRICHMENT_PROVIDERS")
+	if value == "" {
+		return []EnrichmentProvider{{Name: defaultEnrichmentProvider, URL: GetExternalAPIURL()}}
+	}
+
+	var providers []EnrichmentProvider
+	for _, item := range strings.Split(value, ",") {
+		name, url, ok := strings.Cut(strings.TrimSpace(item), "=")
+		name, url = strings.TrimSpace(name), strings.TrimSpace(url)
+		if !ok || name == "" || url == "" {
+			slog.Error(op, "Неверный источник обогащения, источник пропущен",
+				slog.String("key", "ENRICHMENT_PROVIDERS"), slog.String("value", item))
+			continue
+		}
+		providers = append(providers, EnrichmentProvider{Name: name, URL: url})
+	}
+	return providers
+}
+
+// getEnrichmentMerge читает правила слияния полей из ENRICHMENT_MERGE
+// в виде "text=lyrics,default;link=links": поле берётся из первого источника с непустым значением.
+func getEnrichmentMerge(op string) map[string][]string {
+	value := os.Getenv("ENRICHMENT_MERGE")
+	if value == "" {
+		return nil
+	}
+
+	merge := make(map[string][]string)
+	for _, item := range strings.Split(value, ";") {
+		field, list, ok := strings.Cut(strings.TrimSpace(item), "=")
+		field = strings.TrimSpace(field)
+		var names []string
+		for _, name := range strings.Split(list, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+		if !ok || field == "" || len(names) == 0 {
+			slog.Error(op, "Неверное правило слияния, правило пропущено",
+				slog.String("key", "ENRICHMENT_MERGE"), slog.String("value", item))
+			continue
+		}
+		merge[field] = names
+	}
+	return merge
+}
+
+// getString читает строку из переменной окружения key
+func getString(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// ExternalAPI параметры повторов и автоматического выключателя внешнего API
+type ExternalAPI struct {
+	// Retries количество повторов после неудачного запроса, 0 отключает повторы (EXTERNAL_API_RETRIES)
+	Retries int
+	// RetryBaseDelay пауза перед первым повтором, каждая следующая вдвое больше (EXTERNAL_API_RETRY_BASE_DELAY)
+	RetryBaseDelay time.Duration
+	// RetryMaxDelay наибольшая пауза между повторами (EXTERNAL_API_RETRY_MAX_DELAY)
+	RetryMaxDelay time.Duration
+	// BreakerThreshold количество неудач подряд, после которого выключатель открывается (EXTERNAL_API_BREAKER_THRESHOLD)
+	BreakerThreshold int
+	// BreakerTimeout время, на которое открывается выключатель (EXTERNAL_API_BREAKER_TIMEOUT)
+	BreakerTimeout time.Duration
+}
+
+// GetExternalAPI возвращает параметры повторов и выключателя внешнего API, например EXTERNAL_API_RETRIES=3
+func GetExternalAPI() ExternalAPI {
+	const op = "internal.config.GetExternalAPI"
+
+	return ExternalAPI{
+		Retries:          getIntAtLeast(op, "EXTERNAL_API_RETRIES", defaultExternalAPIRetries, 0),
+		RetryBaseDelay:   getDuration(op, "EXTERNAL_API_RETRY_BASE_DELAY", defaultExternalAPIRetryBaseDelay),
+		RetryMaxDelay:    getDuration(op, "EXTERNAL_API_RETRY_MAX_DELAY", defaultExternalAPIRetryMaxDelay),
+		BreakerThreshold: getInt(op, "EXTERNAL_API_BREAKER_THRESHOLD", defaultExternalAPIBreakerThreshold),
+		BreakerTimeout:   getDuration(op, "EXTERNAL_API_BREAKER_TIMEOUT", defaultExternalAPIBreakerTimeout),
+	}
+}
+
+// getInt читает положительное целое число из переменной окружения key
+func getInt(op, key string, defaultValue int) int {
+	return getIntAtLeast(op, key, defaultValue, 1)
+}
+
+// getIntAtLeast читает целое число не меньше minValue из переменной окружения key
+func getIntAtLeast(op, key string, defaultValue, minValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < minValue {
+		slog.Error(op, "Неверное число, используется значение по умолчанию",
+			slog.String("key", key), slog.String("value", value))
+		return defaultValue
+	}
+	return n
+}
@@ -0,0 +1,23 @@
+package config
+
+import "testing"
+
+func TestGetExternalAPIRetries(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int
+	}{
+		{value: "", want: defaultExternalAPIRetries},
+		{value: "0", want: 0},
+		{value: "5", want: 5},
+		{value: "-1", want: defaultExternalAPIRetries},
+		{value: "много", want: defaultExternalAPIRetries},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("EXTERNAL_API_RETRIES", tt.value)
+		if got := GetExternalAPI().Retries; got != tt.want {
+			t.Errorf("EXTERNAL_API_RETRIES=%q: Retries = %d, ожидалось %d", tt.value, got, tt.want)
+		}
+	}
+}
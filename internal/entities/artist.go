@@ -0,0 +1,27 @@
+package entities
+
+// Artist представляет исполнителя (группу).
+// @Description Структура для представления исполнителя, к которому относятся песни.
+// swagger:model Artist
+type Artist struct {
+	// ID уникальный идентификатор исполнителя.
+	//
+	// required: true
+	//
+	// example: 1
+	ID int `json:"id"`
+
+	// Name название группы или исполнителя.
+	//
+	// required: true
+	//
+	// example: "The Beatles"
+	Name string `json:"name"`
+}
+
+func NewArtist(id int, name string) *Artist {
+	return &Artist{
+		ID:   id,
+		Name: name,
+	}
+}
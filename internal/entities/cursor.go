@@ -0,0 +1,58 @@
+package entities
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// Cursor позиция в списке песен для keyset-пагинации.
+// Клиент получает курсор в виде непрозрачной строки и передаёт её обратно без изменений.
+type Cursor struct {
+	// Sort сигнатура сортировки, при которой курсор был выдан
+	Sort string `json:"s"`
+	// Values значения ключей сортировки последней записи страницы (кроме id), nil для NULL
+	Values []*string `json:"v,omitempty"`
+	// ID идентификатор последней записи страницы
+	ID int `json:"id"`
+}
+
+func NewCursor(sort string, id int) *Cursor {
+	return &Cursor{
+		Sort: sort,
+		ID:   id,
+	}
+}
+
+// Encode кодирует курсор в строку, пригодную для query-параметра
+func (c *Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor разбирает строку, полученную от Encode
+func DecodeCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("неверный курсор: %w", err)
+	}
+
+	var c Cursor
+	if err = json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("неверный курсор: %w", err)
+	}
+	return &c, nil
+}
+
+// SongCursorPage страница списка песен при keyset-пагинации.
+// @Description Страница песен и курсор для запроса следующей страницы. next_cursor отсутствует на последней странице.
+// swagger:model SongCursorPage
+type SongCursorPage struct {
+	// Items песни страницы.
+	Items []Song `json:"items"`
+
+	// NextCursor курсор следующей страницы.
+	//
+	// example: "eyJzIjoiaWQiLCJpZCI6MTF9"
+	NextCursor string `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,40 @@
+package entities
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	value := "Muse"
+	date := "2006-07-16"
+
+	tests := []struct {
+		name   string
+		cursor *Cursor
+	}{
+		{name: "только id", cursor: NewCursor("id", 11)},
+		{name: "ключи сортировки", cursor: &Cursor{Sort: "group,-release_date", Values: []*string{&value, &date}, ID: 42}},
+		{name: "NULL в ключе", cursor: &Cursor{Sort: "-release_date", Values: []*string{nil}, ID: 7}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeCursor(tt.cursor.Encode())
+			if err != nil {
+				t.Fatalf("DecodeCursor: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.cursor) {
+				t.Errorf("DecodeCursor(Encode()) = %+v, ожидалось %+v", got, tt.cursor)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	for _, token := range []string{"не base64", "bm90IGpzb24", "W10"} {
+		if _, err := DecodeCursor(token); err == nil {
+			t.Errorf("DecodeCursor(%q) без ошибки", token)
+		}
+	}
+}
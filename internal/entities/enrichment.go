@@ -0,0 +1,149 @@
+package entities
+
+import "time"
+
+// EnrichmentStatus состояние обогащения песни данными внешнего API
+type EnrichmentStatus string
+
+const (
+	// EnrichmentPending песня ждёт обогащения
+	EnrichmentPending EnrichmentStatus = "pending"
+	// EnrichmentDone данные внешнего API получены
+	EnrichmentDone EnrichmentStatus = "done"
+	// EnrichmentFailed все попытки обогащения исчерпаны
+	EnrichmentFailed EnrichmentStatus = "failed"
+)
+
+// JobStatus состояние задачи обогащения
+type JobStatus string
+
+const (
+	JobPending JobStatus = "pending"
+	JobRunning JobStatus = "running"
+	JobDone    JobStatus = "done"
+	JobFailed  JobStatus = "failed"
+)
+
+// EnrichmentJob задача обогащения песни из очереди в DB.
+// @Description Задача обогащения песни: состояние, число попыток и последняя ошибка.
+// swagger:model EnrichmentJob
+type EnrichmentJob struct {
+	// ID идентификатор задачи.
+	//
+	// example: 7
+	ID int `json:"id"`
+
+	// SongID идентификатор песни.
+	//
+	// example: 1
+	SongID int `json:"songId"`
+
+	// Group название группы песни.
+	//
+	// example: "The Beatles"
+	Group string `json:"group"`
+
+	// Title название песни.
+	//
+	// example: "Hey Jude"
+	Title string `json:"song"`
+
+	// Status состояние задачи: pending, running, done или failed.
+	//
+	// example: "failed"
+	Status JobStatus `json:"status"`
+
+	// Overwrite данные внешнего API заменяют уже заполненные поля песни.
+	//
+	// example: false
+	Overwrite bool `json:"overwrite"`
+
+	// Attempts количество выполненных попыток.
+	//
+	// example: 3
+	Attempts int `json:"attempts"`
+
+	// LastError ошибка последней неудачной попытки.
+	//
+	// example: "ошибка внешнего API: внешнее API вернуло статус 503"
+	LastError string `json:"lastError,omitempty"`
+
+	// RunAt время, не раньше которого задача будет выполнена.
+	//
+	// example: "2025-04-05T10:00:30Z"
+	RunAt time.Time `json:"runAt"`
+
+	// CreatedAt время постановки задачи в очередь.
+	//
+	// example: "2025-04-05T10:00:00Z"
+	CreatedAt time.Time `json:"createdAt"`
+
+	// UpdatedAt время последнего изменения задачи.
+	//
+	// example: "2025-04-05T10:00:31Z"
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	// LeaseToken номер выдачи задачи воркеру. Воркер завершает задачу, только пока номер не сменился.
+	LeaseToken int64 `json:"-"`
+}
+
+// SongEnrichment состояние обогащения песни.
+// @Description Статус обогащения песни и её последняя задача обогащения.
+// swagger:model SongEnrichment
+type SongEnrichment struct {
+	// SongID идентификатор песни.
+	//
+	// example: 1
+	SongID int `json:"songId"`
+
+	// Status статус обогащения: pending, done или failed.
+	//
+	// example: "pending"
+	Status EnrichmentStatus `json:"status"`
+
+	// Job последняя задача обогащения, отсутствует у песен, обогащённых до появления очереди.
+	Job *EnrichmentJob `json:"job,omitempty"`
+}
+
+// EnrichmentCacheEntry сохранённый ответ источников обогащения.
+// @Description Ответ источников обогащения для группы и названия песни и срок его хранения.
+// swagger:model EnrichmentCacheEntry
+type EnrichmentCacheEntry struct {
+	// ID идентификатор записи.
+	//
+	// example: 3
+	ID int `json:"id"`
+
+	// Group название группы, по которому был запрос.
+	//
+	// example: "The Beatles"
+	Group string `json:"group"`
+
+	// Title название песни, по которому был запрос.
+	//
+	// example: "Hey Jude"
+	Title string `json:"song"`
+
+	// Found источники знают песню. Для false запоминается ответ 404 и поля данных пусты.
+	//
+	// example: true
+	Found bool `json:"found"`
+
+	// Info данные песни от источников.
+	Info ExternalSongInfo `json:"info"`
+
+	// Expired срок хранения записи истёк, при следующем запросе она будет заменена.
+	//
+	// example: false
+	Expired bool `json:"expired"`
+
+	// CreatedAt время сохранения ответа.
+	//
+	// example: "2025-04-07T10:00:00Z"
+	CreatedAt time.Time `json:"createdAt"`
+
+	// ExpiresAt время, после которого ответ запрашивается заново.
+	//
+	// example: "2025-04-08T10:00:00Z"
+	ExpiresAt time.Time `json:"expiresAt"`
+}
@@ -1,14 +1,79 @@
 package entities
 
+// ErrorCode машиночитаемый код ошибки. Коды не меняются между версиями API,
+// поэтому клиенты могут ветвиться по ним вместо разбора текста сообщения.
+type ErrorCode string
+
+const (
+	CodeInvalidID            ErrorCode = "invalid_id"
+	CodeInvalidBody          ErrorCode = "invalid_body"
+	CodeInvalidParameter     ErrorCode = "invalid_parameter"
+	CodeInvalidFilter        ErrorCode = "invalid_filter"
+	CodeInvalidSort          ErrorCode = "invalid_sort"
+	CodeInvalidCursor        ErrorCode = "invalid_cursor"
+	CodeInvalidFile          ErrorCode = "invalid_file"
+	CodeInvalidIfMatch       ErrorCode = "invalid_if_match"
+	CodePayloadTooLarge      ErrorCode = "payload_too_large"
+	CodeUnsupportedMediaType ErrorCode = "unsupported_media_type"
+	CodePatchTestFailed      ErrorCode = "patch_test_failed"
+	CodeNotFound             ErrorCode = "not_found"
+	CodeRouteNotFound        ErrorCode = "route_not_found"
+	CodeMethodNotAllowed     ErrorCode = "method_not_allowed"
+	CodeConflict             ErrorCode = "conflict"
+	CodeDuplicateSong        ErrorCode = "duplicate_song"
+	CodeVersionMismatch      ErrorCode = "version_mismatch"
+	CodeValidationFailed     ErrorCode = "validation_failed"
+	CodeUpstreamError        ErrorCode = "upstream_error"
+	CodeInternalError        ErrorCode = "internal_error"
+)
+
 // ErrorResponse описывает структуру ошибки
-// @Description Структура для представления ошибки, которая включает код ошибки и сообщение.
-// @Example { "code": 500, "message": "Internal Server Error" }
+// @Description Ошибка в формате application/problem+json (RFC 7807) с машиночитаемым кодом и ID запроса.
 type ErrorResponse struct {
-	// Код ошибки
-	// @example 500
-	Code int `json:"code"`
+	// Тип ошибки, URI вида urn:problem:<code>
+	// @example urn:problem:not_found
+	Type string `json:"type"`
+
+	// Краткое описание типа ошибки
+	// @example Not Found
+	Title string `json:"title"`
+
+	// Код ответа HTTP
+	// @example 404
+	Status int `json:"status"`
+
+	// Машиночитаемый код ошибки
+	// @example not_found
+	Code ErrorCode `json:"code"`
 
 	// Сообщение ошибки
-	// @example "Internal Server Error"
+	// @example песня 42: не найдено
+	Detail string `json:"detail,omitempty"`
+
+	// Путь запроса, вызвавшего ошибку
+	// @example /songs/42
+	Instance string `json:"instance,omitempty"`
+
+	// ID запроса, он же передаётся в заголовке X-Request-ID
+	// @example 4f1c2a7e9b3d5f60
+	RequestID string `json:"requestId,omitempty"`
+
+	// Ошибки отдельных полей запроса
+	Details []FieldError `json:"details,omitempty"`
+
+	// ID уже существующей песни для ошибки duplicate_song
+	// @example 42
+	ExistingID int `json:"existingId,omitempty"`
+}
+
+// FieldError ошибка одного поля запроса
+// @Description Поле запроса, не прошедшее проверку, и причина ошибки.
+type FieldError struct {
+	// Имя поля или параметра запроса
+	// @example threshold
+	Field string `json:"field"`
+
+	// Причина ошибки
+	// @example ожидается число от 0 до 1
 	Message string `json:"message"`
 }
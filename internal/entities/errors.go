@@ -0,0 +1,65 @@
+package entities
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Ошибки предметной области. Репозиторий и сценарии оборачивают их (fmt.Errorf с %w),
+// а слой handler переводит в код ответа HTTP.
+var (
+	// ErrNotFound запись не найдена
+	ErrNotFound = errors.New("не найдено")
+	// ErrConflict запись противоречит уже существующим данным
+	ErrConflict = errors.New("конфликт с существующими данными")
+	// ErrValidation данные запроса не прошли проверку
+	ErrValidation = errors.New("неверные данные")
+	// ErrUpstream внешний API недоступен или ответил ошибкой
+	ErrUpstream = errors.New("ошибка внешнего API")
+)
+
+// ErrSongInfoNotFound источник обогащения ответил, что не знает песню
+var ErrSongInfoNotFound = fmt.Errorf("%w: песня не найдена", ErrUpstream)
+
+// ErrVersionConflict версия записи в DB не совпадает с версией, на которую рассчитывал клиент
+var ErrVersionConflict = errors.New("версия записи изменилась")
+
+// ErrInvalidReleaseDate дата выпуска не распознана ни в одном из известных форматов
+var ErrInvalidReleaseDate = fmt.Errorf("%w: неверная дата выпуска", ErrValidation)
+
+// ErrDiffTooLarge в тексте ревизии больше строк, чем готов сравнивать diff
+var ErrDiffTooLarge = fmt.Errorf("%w: текст слишком длинный для сравнения", ErrValidation)
+
+// ErrDuplicateSong песня с такой же группой и названием уже есть в библиотеке
+var ErrDuplicateSong = fmt.Errorf("%w: песня уже существует", ErrConflict)
+
+// DuplicateSongError сообщает ID песни, которую повторяет новая
+type DuplicateSongError struct {
+	ID int
+}
+
+func (e *DuplicateSongError) Error() string {
+	return fmt.Sprintf("%s (id %d)", ErrDuplicateSong, e.ID)
+}
+
+func (e *DuplicateSongError) Unwrap() error {
+	return ErrDuplicateSong
+}
+
+// ValidationError перечисляет все поля, не прошедшие проверку
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, field.Field+": "+field.Message)
+	}
+	return fmt.Sprintf("%s: %s", ErrValidation, strings.Join(messages, "; "))
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrValidation
+}
@@ -0,0 +1,184 @@
+package entities
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// MatchMode задаёт способ сравнения значения фильтра со значением в DB
+type MatchMode string
+
+const (
+	// MatchExact точное совпадение
+	MatchExact MatchMode = "exact"
+	// MatchPrefix совпадение начала строки без учёта регистра
+	MatchPrefix MatchMode = "prefix"
+	// MatchContains вхождение подстроки без учёта регистра
+	MatchContains MatchMode = "contains"
+	// MatchFuzzy нечёткое совпадение по триграммам (pg_trgm)
+	MatchFuzzy MatchMode = "fuzzy"
+)
+
+// ParseMatchMode разбирает способ сравнения, пустая строка означает точное совпадение
+func ParseMatchMode(s string) (MatchMode, error) {
+	switch mode := MatchMode(s); mode {
+	case "":
+		return MatchExact, nil
+	case MatchExact, MatchPrefix, MatchContains, MatchFuzzy:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("неизвестный способ сравнения %q", s)
+	}
+}
+
+// FilterOp возвращает операцию фильтра, соответствующую способу сравнения
+func (m MatchMode) FilterOp() FilterOp {
+	switch m {
+	case MatchPrefix:
+		return OpPrefix
+	case MatchContains:
+		return OpContains
+	case MatchFuzzy:
+		return OpFuzzy
+	default:
+		return OpEq
+	}
+}
+
+// FilterField поле песни, по которому можно фильтровать
+type FilterField string
+
+const (
+	FieldID          FilterField = "id"
+	FieldArtistID    FilterField = "artist_id"
+	FieldGroup       FilterField = "group"
+	FieldTitle       FilterField = "title"
+	FieldReleaseDate FilterField = "release_date"
+	FieldReleaseYear FilterField = "release_year"
+	FieldText        FilterField = "text"
+	FieldLink        FilterField = "link"
+)
+
+// FilterOp операция сравнения в условии фильтра
+type FilterOp string
+
+const (
+	OpEq       FilterOp = "eq"
+	OpNe       FilterOp = "ne"
+	OpIn       FilterOp = "in"
+	OpGt       FilterOp = "gt"
+	OpGte      FilterOp = "gte"
+	OpLt       FilterOp = "lt"
+	OpLte      FilterOp = "lte"
+	OpIsNull   FilterOp = "is_null"
+	OpNotNull  FilterOp = "not_null"
+	OpPrefix   FilterOp = "prefix"
+	OpContains FilterOp = "contains"
+	OpFuzzy    FilterOp = "fuzzy"
+)
+
+// fieldKind тип значения поля фильтра
+type fieldKind int
+
+const (
+	kindInt fieldKind = iota
+	kindText
+	kindDate
+)
+
+// filterFields описание полей фильтра: тип значения и допустимость NULL
+var filterFields = map[FilterField]struct {
+	kind     fieldKind
+	nullable bool
+}{
+	FieldID:          {kind: kindInt},
+	FieldArtistID:    {kind: kindInt},
+	FieldGroup:       {kind: kindText},
+	FieldTitle:       {kind: kindText},
+	FieldReleaseDate: {kind: kindDate, nullable: true},
+	FieldReleaseYear: {kind: kindInt, nullable: true},
+	FieldText:        {kind: kindText, nullable: true},
+	FieldLink:        {kind: kindText, nullable: true},
+}
+
+// FilterCondition условие фильтра: поле, операция и значения
+type FilterCondition struct {
+	Field  FilterField
+	Op     FilterOp
+	Values []string
+}
+
+// NewFilterCondition проверяет условие и приводит значения к виду, понятному DB.
+// Неполная дата выпуска (2006, 2006-07) для gt и lte означает конец периода, для остальных операций - начало.
+func NewFilterCondition(field FilterField, op FilterOp, values ...string) (FilterCondition, error) {
+	cond := FilterCondition{Field: field, Op: op}
+
+	desc, ok := filterFields[field]
+	if !ok {
+		return cond, fmt.Errorf("фильтрация по полю %q недоступна", field)
+	}
+
+	switch op {
+	case OpIsNull, OpNotNull:
+		if !desc.nullable {
+			return cond, fmt.Errorf("поле %q не может быть пустым", field)
+		}
+		if len(values) != 0 {
+			return cond, fmt.Errorf("операция %q не принимает значений", op)
+		}
+		return cond, nil
+	case OpPrefix, OpContains, OpFuzzy:
+		if desc.kind != kindText {
+			return cond, fmt.Errorf("операция %q доступна только для текстовых полей", op)
+		}
+	case OpIn:
+		if len(values) == 0 {
+			return cond, fmt.Errorf("операция %q требует хотя бы одно значение", op)
+		}
+	case OpEq, OpNe, OpGt, OpGte, OpLt, OpLte:
+	default:
+		return cond, fmt.Errorf("неизвестная операция фильтра %q", op)
+	}
+	if op != OpIn && len(values) != 1 {
+		return cond, fmt.Errorf("операция %q требует ровно одно значение", op)
+	}
+
+	cond.Values = make([]string, 0, len(values))
+	for _, value := range values {
+		switch desc.kind {
+		case kindInt:
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return cond, fmt.Errorf("значение %q поля %q должно быть целым числом", value, field)
+			}
+			value = strconv.Itoa(n)
+		case kindDate:
+			date, err := ParseReleaseDate(value)
+			if err != nil {
+				return cond, fmt.Errorf("значение %q поля %q должно быть датой", value, field)
+			}
+			bound := date.Date
+			if op == OpGt || op == OpLte {
+				bound = date.End()
+			}
+			value = bound.Format("2006-01-02")
+		}
+		cond.Values = append(cond.Values, value)
+	}
+	return cond, nil
+}
+
+// SongFilter набор условий фильтрации списка песен, объединённых через AND
+type SongFilter struct {
+	Conditions []FilterCondition
+}
+
+// Add проверяет условие и добавляет его в фильтр
+func (f *SongFilter) Add(field FilterField, op FilterOp, values ...string) error {
+	cond, err := NewFilterCondition(field, op, values...)
+	if err != nil {
+		return err
+	}
+	f.Conditions = append(f.Conditions, cond)
+	return nil
+}
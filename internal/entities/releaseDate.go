@@ -0,0 +1,92 @@
+package entities
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DatePrecision точность даты выпуска
+type DatePrecision string
+
+const (
+	PrecisionYear  DatePrecision = "year"
+	PrecisionMonth DatePrecision = "month"
+	PrecisionDay   DatePrecision = "day"
+)
+
+// releaseDateLayouts форматы дат, встречающиеся во внешних API, с их точностью
+var releaseDateLayouts = []struct {
+	layout    string
+	precision DatePrecision
+}{
+	{"2006-01-02", PrecisionDay},
+	{"02.01.2006", PrecisionDay},
+	{"2006/01/02", PrecisionDay},
+	{time.RFC3339, PrecisionDay},
+	{"2 January 2006", PrecisionDay},
+	{"January 2, 2006", PrecisionDay},
+	{"2 Jan 2006", PrecisionDay},
+	{"Jan 2, 2006", PrecisionDay},
+	{"2006-01", PrecisionMonth},
+	{"01.2006", PrecisionMonth},
+	{"January 2006", PrecisionMonth},
+	{"Jan 2006", PrecisionMonth},
+	{"2006", PrecisionYear},
+}
+
+// ReleaseDateFormats возвращает принимаемые форматы даты выпуска в виде примеров на 2 января 2006
+func ReleaseDateFormats() []string {
+	sample := time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC)
+	formats := make([]string, len(releaseDateLayouts))
+	for i, l := range releaseDateLayouts {
+		formats[i] = sample.Format(l.layout)
+	}
+	return formats
+}
+
+// ReleaseDate дата выпуска с точностью до года, месяца или дня
+type ReleaseDate struct {
+	Date      time.Time
+	Precision DatePrecision
+}
+
+// ParseReleaseDate разбирает дату выпуска в любом из известных форматов
+func ParseReleaseDate(s string) (*ReleaseDate, error) {
+	s = strings.TrimSpace(s)
+	for _, l := range releaseDateLayouts {
+		date, err := time.Parse(l.layout, s)
+		if err != nil {
+			continue
+		}
+		return &ReleaseDate{
+			Date:      time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC),
+			Precision: l.precision,
+		}, nil
+	}
+	return nil, fmt.Errorf("неизвестный формат даты выпуска %q", s)
+}
+
+// String возвращает дату в ISO 8601 с учётом точности: 2006, 2006-07 или 2006-07-16
+func (d ReleaseDate) String() string {
+	switch d.Precision {
+	case PrecisionYear:
+		return d.Date.Format("2006")
+	case PrecisionMonth:
+		return d.Date.Format("2006-01")
+	default:
+		return d.Date.Format("2006-01-02")
+	}
+}
+
+// End возвращает последний день периода, который обозначает дата: 2006 - это 31.12.2006
+func (d ReleaseDate) End() time.Time {
+	switch d.Precision {
+	case PrecisionYear:
+		return d.Date.AddDate(1, 0, -1)
+	case PrecisionMonth:
+		return d.Date.AddDate(0, 1, -1)
+	default:
+		return d.Date
+	}
+}
@@ -1,7 +1,9 @@
 package entities
 
+import "time"
+
 // Song представляет информацию о песне.
-// @Description Структура для представления песни, которая включает 6 полей.
+// @Description Структура для представления песни, которая включает 12 полей.
 // swagger:model Song
 type Song struct {
 	// ID уникальный идентификатор песни.
@@ -11,6 +13,11 @@ type Song struct {
 	// example: 1
 	ID int `json:"id"`
 
+	// ArtistID идентификатор исполнителя из таблицы artists.
+	//
+	// example: 1
+	ArtistID int `json:"artistId,omitempty"`
+
 	// Group название группы или исполнителя.
 	//
 	// required: true
@@ -25,11 +32,17 @@ type Song struct {
 	// example: "Hey Jude"
 	Title string `json:"song"`
 
-	// ReleaseDate дата выпуска песни в формате YYYY-MM-DD.
+	// ReleaseDate дата выпуска песни в формате ISO 8601 с учётом точности: YYYY, YYYY-MM или YYYY-MM-DD.
+	// При записи принимаются также форматы внешних API, например DD.MM.YYYY.
 	//
 	// example: "2023-01-01"
 	ReleaseDate string `json:"releaseDate,omitempty"`
 
+	// ReleaseDatePrecision точность даты выпуска: year, month или day.
+	//
+	// example: "day"
+	ReleaseDatePrecision DatePrecision `json:"releaseDatePrecision,omitempty"`
+
 	// Text текст песни.
 	//
 	// example: "Hey, Jude, don't make it bad..."
@@ -39,6 +52,26 @@ type Song struct {
 	//
 	// example: "https://example.com/song-info"
 	Link string `json:"link,omitempty"`
+
+	// Version номер версии записи, увеличивается при каждом изменении. Передаётся в ETag.
+	//
+	// example: 3
+	Version int `json:"version,omitempty"`
+
+	// EnrichmentStatus статус обогащения данными внешнего API: pending, done или failed.
+	//
+	// example: "done"
+	EnrichmentStatus EnrichmentStatus `json:"enrichmentStatus,omitempty"`
+
+	// Similarity коэффициент сходства с фильтром при нечётком поиске (от 0 до 1).
+	//
+	// example: 0.63
+	Similarity *float64 `json:"similarity,omitempty"`
+
+	// DeletedAt время перемещения песни в корзину, заполнено только у песен из корзины.
+	//
+	// example: "2025-03-25T10:00:00Z"
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
 }
 
 func NewSong(
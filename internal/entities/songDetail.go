@@ -0,0 +1,72 @@
+package entities
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SongInclude связанные данные, которые можно встроить в ответ GET /songs/{id}
+type SongInclude string
+
+const (
+	// IncludeVerses количество куплетов текста
+	IncludeVerses SongInclude = "verses"
+	// IncludeRevisions количество ревизий песни
+	IncludeRevisions SongInclude = "revisions"
+	// IncludeArtist исполнитель с количеством его песен
+	IncludeArtist SongInclude = "artist"
+)
+
+// SongIncludes набор запрошенных связанных данных
+type SongIncludes map[SongInclude]bool
+
+// ParseSongIncludes разбирает параметр include вида "verses,revisions,artist"
+func ParseSongIncludes(s string) (SongIncludes, error) {
+	includes := make(SongIncludes)
+	if strings.TrimSpace(s) == "" {
+		return includes, nil
+	}
+
+	for _, part := range strings.Split(s, ",") {
+		include := SongInclude(strings.TrimSpace(part))
+		switch include {
+		case IncludeVerses, IncludeRevisions, IncludeArtist:
+			includes[include] = true
+		default:
+			return nil, fmt.Errorf("неизвестное значение include %q", include)
+		}
+	}
+	return includes, nil
+}
+
+// SongDetail песня со встроенными связанными данными.
+// @Description Песня целиком и связанные данные, запрошенные параметром include.
+// swagger:model SongDetail
+type SongDetail struct {
+	Song
+
+	// VerseCount количество куплетов (строк) текста, заполняется при include=verses.
+	//
+	// example: 12
+	VerseCount *int `json:"verseCount,omitempty"`
+
+	// RevisionCount количество ревизий песни, заполняется при include=revisions.
+	//
+	// example: 3
+	RevisionCount *int `json:"revisionCount,omitempty"`
+
+	// Artist исполнитель песни, заполняется при include=artist.
+	Artist *ArtistSummary `json:"artist,omitempty"`
+}
+
+// ArtistSummary исполнитель с количеством его песен.
+// @Description Исполнитель и количество его песен в библиотеке (без корзины).
+// swagger:model ArtistSummary
+type ArtistSummary struct {
+	Artist
+
+	// SongCount количество песен исполнителя.
+	//
+	// example: 24
+	SongCount int `json:"songCount"`
+}
@@ -0,0 +1,23 @@
+package entities
+
+// SongDuplicate пара похожих песен из отчёта о дубликатах
+// @Description Две песни, у которых похожи группа и название. Песню duplicate можно объединить с song через POST /songs/{id}/merge.
+type SongDuplicate struct {
+	// Песня с меньшим ID
+	Song Song `json:"song"`
+
+	// Похожая на неё песня
+	Duplicate Song `json:"duplicate"`
+
+	// Среднее сходства групп и названий от 0 до 1
+	// @example 0.87
+	Similarity float64 `json:"similarity"`
+}
+
+// SongMergeRequest запрос на объединение песен
+// @Description Песня sourceId объединяется с песней из пути запроса: её данные дополняют пустые поля, а сама она перемещается в корзину.
+type SongMergeRequest struct {
+	// ID песни, которая объединяется с песней из пути запроса
+	// @example 42
+	SourceID int `json:"sourceId"`
+}
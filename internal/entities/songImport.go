@@ -0,0 +1,87 @@
+package entities
+
+// Статусы строк отчёта об импорте
+const (
+	ImportCreated          = "created"
+	ImportWouldCreate      = "would_create"
+	ImportSkippedDuplicate = "skipped_duplicate"
+	ImportFailed           = "failed"
+)
+
+// ImportRow строка файла импорта
+type ImportRow struct {
+	// Line номер строки в файле
+	Line int
+	// Song данные песни, поля releaseDate, text и link необязательны
+	Song Song
+	// Err ошибка разбора строки, такая строка сразу попадает в отчёт как failed
+	Err error
+}
+
+// ImportRowResult результат импорта одной строки.
+// @Description Результат обработки строки файла: status принимает значения created, would_create (dry-run), skipped_duplicate или failed.
+// swagger:model ImportRowResult
+type ImportRowResult struct {
+	// Line номер строки в файле.
+	//
+	// example: 2
+	Line int `json:"line"`
+
+	// Group название группы.
+	//
+	// example: "Muse"
+	Group string `json:"group"`
+
+	// Title название песни.
+	//
+	// example: "Supermassive Black Hole"
+	Title string `json:"song"`
+
+	// Status результат обработки строки.
+	//
+	// example: "created"
+	Status string `json:"status"`
+
+	// ID идентификатор созданной или уже существующей песни.
+	//
+	// example: 42
+	ID int `json:"id,omitempty"`
+
+	// Error причина ошибки для строк со статусом failed.
+	//
+	// example: "неверные данные: song: обязательное поле"
+	Error string `json:"error,omitempty"`
+}
+
+// ImportReport отчёт об импорте файла.
+// @Description Итоги импорта и результат по каждой строке в порядке следования в файле.
+// swagger:model ImportReport
+type ImportReport struct {
+	// DryRun импорт выполнен без записи в DB.
+	//
+	// example: false
+	DryRun bool `json:"dryRun"`
+
+	// Total количество строк в файле.
+	//
+	// example: 3
+	Total int `json:"total"`
+
+	// Created количество созданных (в dry-run - подлежащих созданию) песен.
+	//
+	// example: 1
+	Created int `json:"created"`
+
+	// Skipped количество пропущенных дубликатов.
+	//
+	// example: 1
+	Skipped int `json:"skipped"`
+
+	// Failed количество строк с ошибками.
+	//
+	// example: 1
+	Failed int `json:"failed"`
+
+	// Rows результаты по строкам.
+	Rows []ImportRowResult `json:"rows"`
+}
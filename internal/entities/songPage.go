@@ -0,0 +1,34 @@
+package entities
+
+// SongPage страница списка песен с общим количеством и ссылками на соседние страницы.
+// @Description Ответ GET /songs?envelope=true. Ссылки next и prev отсутствуют на крайних страницах.
+// swagger:model SongPage
+type SongPage struct {
+	// Items песни страницы.
+	Items []Song `json:"items"`
+
+	// Total общее количество песен, подходящих под фильтр.
+	//
+	// example: 440
+	Total int `json:"total"`
+
+	// Limit размер страницы.
+	//
+	// example: 11
+	Limit int `json:"limit"`
+
+	// Offset сдвиг страницы.
+	//
+	// example: 22
+	Offset int `json:"offset"`
+
+	// Next ссылка на следующую страницу.
+	//
+	// example: "/songs?envelope=true&limit=11&offset=33"
+	Next string `json:"next,omitempty"`
+
+	// Prev ссылка на предыдущую страницу.
+	//
+	// example: "/songs?envelope=true&limit=11&offset=11"
+	Prev string `json:"prev,omitempty"`
+}
@@ -0,0 +1,77 @@
+package entities
+
+// SongPatch частичное изменение песни: nil означает, что поле не меняется.
+// @Description Тело PATCH /songs/{id} в формате JSON Merge Patch. Передаются только изменяемые поля.
+// swagger:model SongPatch
+type SongPatch struct {
+	// Group новое название группы или исполнителя.
+	//
+	// example: "The Beatles"
+	Group *string `json:"group,omitempty"`
+
+	// Title новое название песни.
+	//
+	// example: "Hey Jude"
+	Title *string `json:"song,omitempty"`
+
+	// ReleaseDate новая дата выпуска песни.
+	//
+	// example: "2023-01-01"
+	ReleaseDate *string `json:"releaseDate,omitempty"`
+
+	// Text новый текст песни.
+	//
+	// example: "Hey, Jude, don't make it bad..."
+	Text *string `json:"text,omitempty"`
+
+	// Link новая ссылка на дополнительную информацию о песне.
+	//
+	// example: "https://example.com/song-info"
+	Link *string `json:"link,omitempty"`
+}
+
+// IsEmpty сообщает, что патч не меняет ни одного поля
+func (p SongPatch) IsEmpty() bool {
+	return p.Group == nil && p.Title == nil && p.ReleaseDate == nil && p.Text == nil && p.Link == nil
+}
+
+// Field возвращает указатель на поле патча по его имени в JSON, nil для неизвестного имени
+func (p *SongPatch) Field(name string) **string {
+	switch name {
+	case "group":
+		return &p.Group
+	case "song":
+		return &p.Title
+	case "releaseDate":
+		return &p.ReleaseDate
+	case "text":
+		return &p.Text
+	case "link":
+		return &p.Link
+	default:
+		return nil
+	}
+}
+
+// SongField возвращает значение поля песни по его имени в JSON
+func SongField(song *Song, name string) (string, bool) {
+	switch name {
+	case "group":
+		return song.Group, true
+	case "song":
+		return song.Title, true
+	case "releaseDate":
+		return song.ReleaseDate, true
+	case "text":
+		return song.Text, true
+	case "link":
+		return song.Link, true
+	default:
+		return "", false
+	}
+}
+
+// RequiredSongField сообщает, что поле песни нельзя удалить патчем
+func RequiredSongField(name string) bool {
+	return name == "group" || name == "song"
+}
@@ -0,0 +1,79 @@
+package entities
+
+import "time"
+
+// SongRevision представляет сохранённую версию песни.
+// @Description Ревизия песни: номер, операция, которая её создала, и снимок данных песни на тот момент.
+// swagger:model SongRevision
+type SongRevision struct {
+	// SongID идентификатор песни.
+	//
+	// example: 1
+	SongID int `json:"songId"`
+
+	// Revision номер ревизии в пределах песни, начиная с 1.
+	//
+	// example: 3
+	Revision int `json:"revision"`
+
+	// Operation операция, создавшая ревизию: create, update, delete или restore.
+	//
+	// example: "update"
+	Operation string `json:"operation"`
+
+	// CreatedAt время создания ревизии.
+	//
+	// example: "2025-03-27T15:00:00Z"
+	CreatedAt time.Time `json:"createdAt"`
+
+	// Snapshot данные песни в этой ревизии.
+	Snapshot Song `json:"snapshot"`
+}
+
+// DiffLine строка построчного сравнения текстов.
+// @Description Строка diff: op принимает значения equal, insert или delete.
+// swagger:model DiffLine
+type DiffLine struct {
+	// Op тип изменения строки: equal, insert или delete.
+	//
+	// example: "insert"
+	Op string `json:"op"`
+
+	// OldLine номер строки в исходном тексте (с 1), 0 для добавленных строк.
+	//
+	// example: 0
+	OldLine int `json:"oldLine,omitempty"`
+
+	// NewLine номер строки в новом тексте (с 1), 0 для удалённых строк.
+	//
+	// example: 4
+	NewLine int `json:"newLine,omitempty"`
+
+	// Text содержимое строки.
+	//
+	// example: "Hey, Jude, don't be afraid"
+	Text string `json:"text"`
+}
+
+// SongRevisionDiff представляет разницу текста песни между двумя ревизиями.
+// @Description Построчный diff поля text между ревизиями from и to.
+// swagger:model SongRevisionDiff
+type SongRevisionDiff struct {
+	// SongID идентификатор песни.
+	//
+	// example: 1
+	SongID int `json:"songId"`
+
+	// From номер исходной ревизии.
+	//
+	// example: 2
+	From int `json:"from"`
+
+	// To номер итоговой ревизии.
+	//
+	// example: 3
+	To int `json:"to"`
+
+	// Lines строки diff.
+	Lines []DiffLine `json:"lines"`
+}
@@ -0,0 +1,41 @@
+package entities
+
+// SongSearchResult представляет песню, найденную полнотекстовым поиском.
+// @Description Результат поиска по тексту: песня, релевантность, фрагмент текста с подсветкой и номера совпавших куплетов.
+// swagger:model SongSearchResult
+type SongSearchResult struct {
+	// ID уникальный идентификатор песни.
+	//
+	// example: 1
+	ID int `json:"id"`
+
+	// ArtistID идентификатор исполнителя.
+	//
+	// example: 1
+	ArtistID int `json:"artistId"`
+
+	// Group название группы или исполнителя.
+	//
+	// example: "The Beatles"
+	Group string `json:"group"`
+
+	// Title название песни.
+	//
+	// example: "Hey Jude"
+	Title string `json:"song"`
+
+	// Rank релевантность песни запросу, чем больше, тем выше.
+	//
+	// example: 0.6079271
+	Rank float64 `json:"rank"`
+
+	// Snippet фрагмент текста с выделенными совпадениями.
+	//
+	// example: "<b>Hey</b>, <b>Jude</b>, don't make it bad"
+	Snippet string `json:"snippet"`
+
+	// Verses номера совпавших куплетов (с 1), совместимые с пагинацией GET /songs/{id}/text.
+	//
+	// example: [1, 5]
+	Verses []int `json:"verses"`
+}
@@ -0,0 +1,72 @@
+package entities
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SortField поле, по которому можно сортировать список песен
+type SortField string
+
+const (
+	SortByID          SortField = "id"
+	SortByGroup       SortField = "group"
+	SortByTitle       SortField = "title"
+	SortByReleaseDate SortField = "release_date"
+)
+
+// sortableFields белый список полей сортировки
+var sortableFields = map[SortField]bool{
+	SortByID:          true,
+	SortByGroup:       true,
+	SortByTitle:       true,
+	SortByReleaseDate: true,
+}
+
+// SortKey поле сортировки и её направление
+type SortKey struct {
+	Field SortField
+	Desc  bool
+}
+
+// SongSort порядок сортировки списка песен, пустой означает порядок по умолчанию
+type SongSort []SortKey
+
+// ParseSongSort разбирает параметр sort вида "group,-release_date,title".
+// Минус перед полем означает сортировку по убыванию, каждое поле можно указать один раз.
+func ParseSongSort(s string) (SongSort, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(s, ",")
+	sort := make(SongSort, 0, len(parts))
+	seen := make(map[SortField]bool, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		key := SortKey{Field: SortField(strings.TrimPrefix(part, "-")), Desc: strings.HasPrefix(part, "-")}
+		if !sortableFields[key.Field] {
+			return nil, fmt.Errorf("сортировка по полю %q недоступна", key.Field)
+		}
+		if seen[key.Field] {
+			return nil, fmt.Errorf("поле %q указано в сортировке повторно", key.Field)
+		}
+		seen[key.Field] = true
+		sort = append(sort, key)
+	}
+	return sort, nil
+}
+
+// String возвращает порядок сортировки в формате параметра sort
+func (s SongSort) String() string {
+	parts := make([]string, 0, len(s))
+	for _, key := range s {
+		if key.Desc {
+			parts = append(parts, "-"+string(key.Field))
+		} else {
+			parts = append(parts, string(key.Field))
+		}
+	}
+	return strings.Join(parts, ",")
+}
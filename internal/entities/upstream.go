@@ -0,0 +1,70 @@
+package entities
+
+import "time"
+
+// BreakerState состояние автоматического выключателя внешнего API
+type BreakerState string
+
+const (
+	// BreakerClosed запросы проходят
+	BreakerClosed BreakerState = "closed"
+	// BreakerOpen запросы сразу завершаются ошибкой, внешнее API не вызывается
+	BreakerOpen BreakerState = "open"
+	// BreakerHalfOpen пропускается один пробный запрос, по его результату выключатель закрывается или снова открывается
+	BreakerHalfOpen BreakerState = "half_open"
+)
+
+// UpstreamStatus состояние источника обогащения с точки зрения сервиса.
+// @Description Состояние автоматического выключателя источника обогащения и счётчики запросов.
+// swagger:model UpstreamStatus
+type UpstreamStatus struct {
+	// Provider имя источника обогащения.
+	//
+	// example: "lyrics"
+	Provider string `json:"provider"`
+
+	// State состояние выключателя: closed, open или half_open.
+	//
+	// example: "open"
+	State BreakerState `json:"state"`
+
+	// ConsecutiveFailures количество неудачных запросов подряд.
+	//
+	// example: 5
+	ConsecutiveFailures int `json:"consecutiveFailures"`
+
+	// FailureThreshold количество неудач подряд, после которого выключатель открывается.
+	//
+	// example: 5
+	FailureThreshold int `json:"failureThreshold"`
+
+	// OpenedAt время последнего открытия выключателя.
+	//
+	// example: "2025-04-06T12:00:00Z"
+	OpenedAt *time.Time `json:"openedAt,omitempty"`
+
+	// RetryAt время, после которого открытый выключатель пропустит пробный запрос.
+	//
+	// example: "2025-04-06T12:00:30Z"
+	RetryAt *time.Time `json:"retryAt,omitempty"`
+
+	// LastError ошибка последнего неудачного запроса.
+	//
+	// example: "ошибка внешнего API: внешнее API вернуло статус 503"
+	LastError string `json:"lastError,omitempty"`
+
+	// Requests количество запросов к внешнему API с запуска сервиса, включая повторы.
+	//
+	// example: 120
+	Requests int64 `json:"requests"`
+
+	// Failures количество неудачных запросов с запуска сервиса.
+	//
+	// example: 7
+	Failures int64 `json:"failures"`
+
+	// Rejected количество вызовов, отклонённых открытым выключателем.
+	//
+	// example: 3
+	Rejected int64 `json:"rejected"`
+}
@@ -0,0 +1,231 @@
+package handler
+
+import (
+	"TestEffectiveMobile/internal/entities"
+	"TestEffectiveMobile/internal/usecase"
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type ArtistHandler interface {
+	ListArtists(w http.ResponseWriter, r *http.Request)
+	GetArtist(w http.ResponseWriter, r *http.Request)
+	CreateArtist(w http.ResponseWriter, r *http.Request)
+	UpdateArtist(w http.ResponseWriter, r *http.Request)
+	DeleteArtist(w http.ResponseWriter, r *http.Request)
+	ListArtistSongs(w http.ResponseWriter, r *http.Request)
+}
+
+type artistHandler struct {
+	useCase usecase.ArtistUseCase
+}
+
+func NewArtistHandler(useCase usecase.ArtistUseCase) ArtistHandler {
+	return &artistHandler{
+		useCase: useCase,
+	}
+}
+
+// ListArtists godoc
+// @Summary Получение списка исполнителей
+// @Description Получает список исполнителей с пагинацией.
+// @Tags artists
+// @Produce json
+// @Param limit query int false "Лимит записей (по умолчанию 11, не больше 1000)"
+// @Param offset query int false "Сдвиг записей"
+// @Success 200 {array} entities.Artist "Список исполнителей"
+// @Failure 400 {object} entities.ErrorResponse "Неверные параметры пагинации"
+// @Failure 500 {object} entities.ErrorResponse "Ошибка сервера"
+// @Router /artists [get]
+func (h *artistHandler) ListArtists(w http.ResponseWriter, r *http.Request) {
+	const op = "internal.handler.ListArtists"
+
+	limit, offset, ok := parsePagination(w, r)
+	if !ok {
+		return
+	}
+
+	artists, err := h.useCase.ListArtists(r.Context(), limit, offset)
+	if err != nil {
+		writeError(w, r, op, err)
+		return
+	}
+	json.NewEncoder(w).Encode(artists)
+}
+
+// GetArtist godoc
+// @Summary Получение исполнителя
+// @Description Возвращает исполнителя по идентификатору.
+// @Tags artists
+// @Produce json
+// @Param id path int true "ID исполнителя"
+// @Success 200 {object} entities.Artist "Исполнитель"
+// @Failure 400 {object} entities.ErrorResponse "Неверный ID"
+// @Failure 404 {object} entities.ErrorResponse "Исполнитель не найден"
+// @Router /artists/{id} [get]
+func (h *artistHandler) GetArtist(w http.ResponseWriter, r *http.Request) {
+	const op = "internal.handler.GetArtist"
+
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		slog.Error(op, "Ошибка парсинга id с параметров", slog.String("error", err.Error()))
+		writeProblem(w, r, http.StatusBadRequest, entities.CodeInvalidID, "Неверный ID")
+		return
+	}
+
+	artist, err := h.useCase.GetArtistByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, op, err)
+		return
+	}
+	json.NewEncoder(w).Encode(artist)
+}
+
+// CreateArtist godoc
+// @Summary Добавление исполнителя
+// @Description Создает нового исполнителя.
+// @Tags artists
+// @Accept json
+// @Produce json
+// @Param artist body entities.Artist true "Данные исполнителя"
+// @Success 201 {object} entities.Artist "Созданный исполнитель"
+// @Failure 400 {object} entities.ErrorResponse "Bad Request"
+// @Failure 409 {object} entities.ErrorResponse "Исполнитель уже существует"
+// @Failure 413 {object} entities.ErrorResponse "Тело запроса слишком большое"
+// @Failure 422 {object} entities.ErrorResponse "Название не прошло проверку, список в details"
+// @Failure 500 {object} entities.ErrorResponse "Internal Server Error"
+// @Router /artists [post]
+func (h *artistHandler) CreateArtist(w http.ResponseWriter, r *http.Request) {
+	const op = "internal.handler.CreateArtist"
+
+	var artist entities.Artist
+	if err := decodeJSON(w, r, &artist); err != nil {
+		slog.Error(op, "Ошибка декодинга данных", slog.String("error", err.Error()))
+		writeDecodeError(w, r, err)
+		return
+	}
+
+	id, err := h.useCase.CreateArtist(r.Context(), artist)
+	if err != nil {
+		writeError(w, r, op, err)
+		return
+	}
+	artist.ID = id
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(artist)
+}
+
+// UpdateArtist godoc
+// @Summary Обновление исполнителя
+// @Description Переименовывает исполнителя по идентификатору. Все его песни получают новое название группы.
+// @Tags artists
+// @Accept json
+// @Param id path int true "ID исполнителя"
+// @Param artist body entities.Artist true "Обновленные данные исполнителя"
+// @Success 200 {string} string "OK"
+// @Failure 400 {object} entities.ErrorResponse "Неверный ID или Bad Request"
+// @Failure 404 {object} entities.ErrorResponse "Исполнитель не найден"
+// @Failure 409 {object} entities.ErrorResponse "Исполнитель с таким названием уже существует"
+// @Failure 413 {object} entities.ErrorResponse "Тело запроса слишком большое"
+// @Failure 422 {object} entities.ErrorResponse "Название не прошло проверку, список в details"
+// @Failure 500 {object} entities.ErrorResponse "Internal Server Error"
+// @Router /artists/{id} [put]
+func (h *artistHandler) UpdateArtist(w http.ResponseWriter, r *http.Request) {
+	const op = "internal.handler.UpdateArtist"
+
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		slog.Error(op, "Ошибка парсинга id с параметров", slog.String("error", err.Error()))
+		writeProblem(w, r, http.StatusBadRequest, entities.CodeInvalidID, "Неверный ID")
+		return
+	}
+
+	var artist entities.Artist
+	if err = decodeJSON(w, r, &artist); err != nil {
+		slog.Error(op, "Ошибка декодинга данных", slog.String("error", err.Error()))
+		writeDecodeError(w, r, err)
+		return
+	}
+	artist.ID = id
+	if err = h.useCase.UpdateArtist(r.Context(), artist); err != nil {
+		writeError(w, r, op, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// DeleteArtist godoc
+// @Summary Удаление исполнителя
+// @Description Удаляет исполнителя по идентификатору. Исполнителя, у которого есть песни, удалить нельзя.
+// @Tags artists
+// @Param id path int true "ID исполнителя"
+// @Success 204 {string} string "No Content"
+// @Failure 400 {object} entities.ErrorResponse "Неверный ID"
+// @Failure 404 {object} entities.ErrorResponse "Исполнитель не найден"
+// @Failure 409 {object} entities.ErrorResponse "У исполнителя есть песни"
+// @Failure 500 {object} entities.ErrorResponse "Internal Server Error"
+// @Router /artists/{id} [delete]
+func (h *artistHandler) DeleteArtist(w http.ResponseWriter, r *http.Request) {
+	const op = "internal.handler.DeleteArtist"
+
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		slog.Error(op, "Ошибка парсинга id с параметров", slog.String("error", err.Error()))
+		writeProblem(w, r, http.StatusBadRequest, entities.CodeInvalidID, "Неверный ID")
+		return
+	}
+
+	if err = h.useCase.DeleteArtist(r.Context(), id); err != nil {
+		writeError(w, r, op, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListArtistSongs godoc
+// @Summary Получение песен исполнителя
+// @Description Возвращает песни исполнителя с пагинацией.
+// @Tags artists
+// @Produce json
+// @Param id path int true "ID исполнителя"
+// @Param limit query int false "Лимит записей (по умолчанию 11, не больше 1000)"
+// @Param offset query int false "Сдвиг записей"
+// @Success 200 {array} entities.Song "Список песен"
+// @Failure 400 {object} entities.ErrorResponse "Неверный ID или параметры пагинации"
+// @Failure 404 {object} entities.ErrorResponse "Исполнитель не найден"
+// @Failure 500 {object} entities.ErrorResponse "Internal Server Error"
+// @Router /artists/{id}/songs [get]
+func (h *artistHandler) ListArtistSongs(w http.ResponseWriter, r *http.Request) {
+	const op = "internal.handler.ListArtistSongs"
+
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		slog.Error(op, "Ошибка парсинга id с параметров", slog.String("error", err.Error()))
+		writeProblem(w, r, http.StatusBadRequest, entities.CodeInvalidID, "Неверный ID")
+		return
+	}
+
+	if _, err = h.useCase.GetArtistByID(r.Context(), id); err != nil {
+		writeError(w, r, op, err)
+		return
+	}
+
+	limit, offset, ok := parsePagination(w, r)
+	if !ok {
+		return
+	}
+
+	songs, err := h.useCase.ListArtistSongs(r.Context(), id, limit, offset)
+	if err != nil {
+		writeError(w, r, op, err)
+		return
+	}
+	json.NewEncoder(w).Encode(songs)
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"TestEffectiveMobile/internal/entities"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxBodySize максимальный размер JSON-тела запроса
+const maxBodySize = 1 << 20
+
+// decodeJSON читает JSON-тело запроса в dst. Неизвестные поля, данные после объекта
+// и тело больше maxBodySize считаются ошибкой.
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(dst); err != nil {
+		return err
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return errors.New("после JSON-объекта есть лишние данные")
+	}
+	return nil
+}
+
+// writeDecodeError отвечает на ошибку decodeJSON, указывая поле, если его удалось определить
+func writeDecodeError(w http.ResponseWriter, r *http.Request, err error) {
+	var maxBytesErr *http.MaxBytesError
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		writeProblem(w, r, http.StatusRequestEntityTooLarge, entities.CodePayloadTooLarge,
+			fmt.Sprintf("Тело запроса больше %d байт", maxBodySize))
+	case errors.As(err, &typeErr):
+		writeProblem(w, r, http.StatusBadRequest, entities.CodeInvalidBody, "Неверный тип поля",
+			entities.FieldError{Field: typeErr.Field, Message: "ожидается " + typeErr.Type.String()})
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json не экспортирует тип этой ошибки, имя поля есть только в тексте
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		writeProblem(w, r, http.StatusBadRequest, entities.CodeInvalidBody, "Неизвестное поле",
+			entities.FieldError{Field: field, Message: "неизвестное поле"})
+	case errors.As(err, &syntaxErr), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		writeProblem(w, r, http.StatusBadRequest, entities.CodeInvalidBody, "Тело запроса не является корректным JSON")
+	default:
+		writeProblem(w, r, http.StatusBadRequest, entities.CodeInvalidBody, err.Error())
+	}
+}
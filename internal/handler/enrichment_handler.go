@@ -0,0 +1,243 @@
+package handler
+
+import (
+	"TestEffectiveMobile/internal/entities"
+	"TestEffectiveMobile/internal/usecase"
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type EnrichmentHandler interface {
+	GetSongEnrichment(w http.ResponseWriter, r *http.Request)
+	ListFailedJobs(w http.ResponseWriter, r *http.Request)
+	RetryJob(w http.ResponseWriter, r *http.Request)
+	GetUpstreamStatus(w http.ResponseWriter, r *http.Request)
+	ListCacheEntries(w http.ResponseWriter, r *http.Request)
+	DeleteCacheEntry(w http.ResponseWriter, r *http.Request)
+	InvalidateCache(w http.ResponseWriter, r *http.Request)
+	PurgeExpiredCache(w http.ResponseWriter, r *http.Request)
+}
+
+type enrichmentHandler struct {
+	useCase usecase.EnrichmentUseCase
+}
+
+func NewEnrichmentHandler(useCase usecase.EnrichmentUseCase) EnrichmentHandler {
+	return &enrichmentHandler{
+		useCase: useCase,
+	}
+}
+
+// GetSongEnrichment godoc
+// @Summary Статус обогащения песни
+// @Description Возвращает статус обогащения песни данными внешнего API (pending, done или failed)
+// @Description и её последнюю задачу обогащения с числом попыток и последней ошибкой.
+// @Tags enrichment
+// @Produce json
+// @Param id path int true "ID песни"
+// @Success 200 {object} entities.SongEnrichment "Статус обогащения"
+// @Failure 400 {object} entities.ErrorResponse "Неверный ID"
+// @Failure 404 {object} entities.ErrorResponse "Песня не найдена"
+// @Failure 500 {object} entities.ErrorResponse "Internal Server Error"
+// @Router /songs/{id}/enrichment [get]
+func (h *enrichmentHandler) GetSongEnrichment(w http.ResponseWriter, r *http.Request) {
+	const op = "internal.handler.GetSongEnrichment"
+
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		slog.Error(op, "Ошибка парсинга id с параметров", slog.String("error", err.Error()))
+		writeProblem(w, r, http.StatusBadRequest, entities.CodeInvalidID, "Неверный ID")
+		return
+	}
+
+	enrichment, err := h.useCase.GetSongEnrichment(r.Context(), id)
+	if err != nil {
+		writeError(w, r, op, err)
+		return
+	}
+	json.NewEncoder(w).Encode(enrichment)
+}
+
+// ListFailedJobs godoc
+// @Summary Задачи обогащения, завершившиеся ошибкой
+// @Description Возвращает задачи обогащения, у которых исчерпаны все попытки, начиная с последней.
+// @Tags enrichment
+// @Produce json
+// @Param limit query int false "Лимит записей (по умолчанию 11, не больше 1000)"
+// @Param offset query int false "Сдвиг записей"
+// @Success 200 {array} entities.EnrichmentJob "Список задач"
+// @Failure 400 {object} entities.ErrorResponse "Неверные параметры пагинации"
+// @Failure 500 {object} entities.ErrorResponse "Internal Server Error"
+// @Router /admin/enrichment/jobs/failed [get]
+func (h *enrichmentHandler) ListFailedJobs(w http.ResponseWriter, r *http.Request) {
+	const op = "internal.handler.ListFailedJobs"
+
+	limit, offset, ok := parsePagination(w, r)
+	if !ok {
+		return
+	}
+
+	jobs, err := h.useCase.ListFailedJobs(r.Context(), limit, offset)
+	if err != nil {
+		writeError(w, r, op, err)
+		return
+	}
+	json.NewEncoder(w).Encode(jobs)
+}
+
+// RetryJob godoc
+// @Summary Повтор задачи обогащения
+// @Description Возвращает завершившуюся ошибкой задачу в очередь с обнулённым счётчиком попыток,
+// @Description песня снова получает статус обогащения pending.
+// @Tags enrichment
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Success 202 {object} entities.EnrichmentJob "Задача поставлена в очередь"
+// @Failure 400 {object} entities.ErrorResponse "Неверный ID"
+// @Failure 404 {object} entities.ErrorResponse "Задача не найдена"
+// @Failure 409 {object} entities.ErrorResponse "Задача не завершилась ошибкой или у песни уже есть задача в очереди"
+// @Failure 500 {object} entities.ErrorResponse "Internal Server Error"
+// @Router /admin/enrichment/jobs/{id}/retry [post]
+func (h *enrichmentHandler) RetryJob(w http.ResponseWriter, r *http.Request) {
+	const op = "internal.handler.RetryJob"
+
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		slog.Error(op, "Ошибка парсинга id с параметров", slog.String("error", err.Error()))
+		writeProblem(w, r, http.StatusBadRequest, entities.CodeInvalidID, "Неверный ID")
+		return
+	}
+
+	job, err := h.useCase.RetryJob(r.Context(), id)
+	if err != nil {
+		writeError(w, r, op, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job)
+}
+
+// GetUpstreamStatus godoc
+// @Summary Состояние источников обогащения
+// @Description Возвращает для каждого источника обогащения состояние автоматического выключателя
+// @Description (closed, open или half_open), время следующей пробной попытки и счётчики запросов,
+// @Description ошибок и отклонённых вызовов.
+// @Tags enrichment
+// @Produce json
+// @Success 200 {array} entities.UpstreamStatus "Состояние источников обогащения"
+// @Router /admin/enrichment/upstream [get]
+func (h *enrichmentHandler) GetUpstreamStatus(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(h.useCase.UpstreamStatuses())
+}
+
+// ListCacheEntries godoc
+// @Summary Сохранённые ответы источников обогащения
+// @Description Возвращает сохранённые ответы источников обогащения, начиная с последнего, в том числе истёкшие.
+// @Description Записи с found=false запоминают, что источники не знают песню.
+// @Description Группа и название сравниваются без учёта регистра и пробелов по краям.
+// @Tags enrichment
+// @Produce json
+// @Param group query string false "Группа"
+// @Param song query string false "Название песни"
+// @Param limit query int false "Лимит записей (по умолчанию 11, не больше 1000)"
+// @Param offset query int false "Сдвиг записей"
+// @Success 200 {array} entities.EnrichmentCacheEntry "Список записей"
+// @Failure 400 {object} entities.ErrorResponse "Неверные параметры пагинации"
+// @Failure 500 {object} entities.ErrorResponse "Internal Server Error"
+// @Router /admin/enrichment/cache [get]
+func (h *enrichmentHandler) ListCacheEntries(w http.ResponseWriter, r *http.Request) {
+	const op = "internal.handler.ListCacheEntries"
+
+	limit, offset, ok := parsePagination(w, r)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+
+	entries, err := h.useCase.ListCacheEntries(r.Context(), query.Get("group"), query.Get("song"), limit, offset)
+	if err != nil {
+		writeError(w, r, op, err)
+		return
+	}
+	json.NewEncoder(w).Encode(entries)
+}
+
+// DeleteCacheEntry godoc
+// @Summary Удаление сохранённого ответа
+// @Description Удаляет сохранённый ответ источников обогащения, следующий запрос песни обратится к источникам.
+// @Tags enrichment
+// @Param id path int true "ID записи"
+// @Success 204 "Запись удалена"
+// @Failure 400 {object} entities.ErrorResponse "Неверный ID"
+// @Failure 404 {object} entities.ErrorResponse "Запись не найдена"
+// @Failure 500 {object} entities.ErrorResponse "Internal Server Error"
+// @Router /admin/enrichment/cache/{id} [delete]
+func (h *enrichmentHandler) DeleteCacheEntry(w http.ResponseWriter, r *http.Request) {
+	const op = "internal.handler.DeleteCacheEntry"
+
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		slog.Error(op, "Ошибка парсинга id с параметров", slog.String("error", err.Error()))
+		writeProblem(w, r, http.StatusBadRequest, entities.CodeInvalidID, "Неверный ID")
+		return
+	}
+
+	if err = h.useCase.DeleteCacheEntry(r.Context(), id); err != nil {
+		writeError(w, r, op, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// InvalidateCache godoc
+// @Summary Удаление сохранённых ответов
+// @Description Удаляет сохранённые ответы источников обогащения для группы и (или) названия песни.
+// @Description Чтобы удалить все ответы, передайте all=true без group и song.
+// @Tags enrichment
+// @Produce json
+// @Param group query string false "Группа"
+// @Param song query string false "Название песни"
+// @Param all query bool false "Удалить все ответы"
+// @Success 200 {object} map[string]int64 "Количество удалённых записей"
+// @Failure 400 {object} entities.ErrorResponse "Не указаны group, song или all"
+// @Failure 500 {object} entities.ErrorResponse "Internal Server Error"
+// @Router /admin/enrichment/cache [delete]
+func (h *enrichmentHandler) InvalidateCache(w http.ResponseWriter, r *http.Request) {
+	const op = "internal.handler.InvalidateCache"
+
+	query := r.URL.Query()
+	all, _ := strconv.ParseBool(query.Get("all"))
+
+	deleted, err := h.useCase.InvalidateCache(r.Context(), query.Get("group"), query.Get("song"), all)
+	if err != nil {
+		writeError(w, r, op, err)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]int64{"deleted": deleted})
+}
+
+// PurgeExpiredCache godoc
+// @Summary Очистка истёкших ответов
+// @Description Удаляет сохранённые ответы источников обогащения, срок которых истёк (ENRICHMENT_CACHE_TTL, ENRICHMENT_CACHE_NOT_FOUND_TTL).
+// @Description Истёкшие ответы не используются, но остаются в таблице, пока их не удалить.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} map[string]int64 "Количество удалённых записей"
+// @Failure 500 {object} entities.ErrorResponse "Internal Server Error"
+// @Router /admin/enrichment/cache/purge [post]
+func (h *enrichmentHandler) PurgeExpiredCache(w http.ResponseWriter, r *http.Request) {
+	const op = "internal.handler.PurgeExpiredCache"
+
+	purged, err := h.useCase.PurgeExpiredCache(r.Context())
+	if err != nil {
+		writeError(w, r, op, err)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]int64{"purged": purged})
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"TestEffectiveMobile/internal/entities"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+// errorStatus возвращает код ответа HTTP и код ошибки для ошибки предметной области
+func errorStatus(err error) (int, entities.ErrorCode) {
+	switch {
+	case errors.Is(err, entities.ErrNotFound):
+		return http.StatusNotFound, entities.CodeNotFound
+	case errors.Is(err, entities.ErrVersionConflict):
+		return http.StatusPreconditionFailed, entities.CodeVersionMismatch
+	case errors.Is(err, entities.ErrDuplicateSong):
+		return http.StatusConflict, entities.CodeDuplicateSong
+	case errors.Is(err, entities.ErrConflict):
+		return http.StatusConflict, entities.CodeConflict
+	case errors.Is(err, entities.ErrValidation):
+		return http.StatusUnprocessableEntity, entities.CodeValidationFailed
+	case errors.Is(err, entities.ErrUpstream):
+		return http.StatusBadGateway, entities.CodeUpstreamError
+	default:
+		return http.StatusInternalServerError, entities.CodeInternalError
+	}
+}
+
+// writeError отвечает на ошибку сценария: 404 ErrNotFound, 409 ErrConflict, 412 ErrVersionConflict,
+// 422 ErrValidation (ошибки полей из *entities.ValidationError попадают в details), 502 ErrUpstream, остальные ошибки 500.
+// Клиент видит текст только ошибок запроса, подробности ошибок сервера попадают лишь в лог.
+func writeError(w http.ResponseWriter, r *http.Request, op string, err error) {
+	status, code := errorStatus(err)
+	problem := newProblem(r, status, code, err.Error())
+
+	var duplicate *entities.DuplicateSongError
+	var validationErr *entities.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		problem.Detail = "Данные не прошли проверку"
+		problem.Details = validationErr.Fields
+	case errors.As(err, &duplicate):
+		problem.ExistingID = duplicate.ID
+		w.Header().Set("Location", fmt.Sprintf("/songs/%d", duplicate.ID))
+	case status == http.StatusBadGateway:
+		slog.Error(op, "Ошибка внешнего API", slog.String("error", err.Error()),
+			slog.String("requestId", problem.RequestID))
+		problem.Detail = entities.ErrUpstream.Error()
+	case status >= http.StatusInternalServerError:
+		slog.Error(op, "Ошибка сервера", slog.String("error", err.Error()),
+			slog.String("requestId", problem.RequestID))
+		problem.Detail = "Internal Server Error"
+	}
+	writeProblemResponse(w, problem)
+}
+
+// writeProblem отвечает ошибкой запроса с кодом code и сообщением detail
+func writeProblem(
+	w http.ResponseWriter,
+	r *http.Request,
+	status int,
+	code entities.ErrorCode,
+	detail string,
+	details ...entities.FieldError,
+) {
+	problem := newProblem(r, status, code, detail)
+	problem.Details = details
+	writeProblemResponse(w, problem)
+}
+
+func newProblem(r *http.Request, status int, code entities.ErrorCode, detail string) entities.ErrorResponse {
+	return entities.ErrorResponse{
+		Type:      "urn:problem:" + string(code),
+		Title:     http.StatusText(status),
+		Status:    status,
+		Code:      code,
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		RequestID: requestIDFrom(r.Context()),
+	}
+}
+
+func writeProblemResponse(w http.ResponseWriter, problem entities.ErrorResponse) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(problem.Status)
+	json.NewEncoder(w).Encode(problem)
+}
+
+// NotFound отвечает на запрос к неизвестному маршруту
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	writeProblem(w, r, http.StatusNotFound, entities.CodeRouteNotFound, "Маршрут не найден")
+}
+
+// MethodNotAllowed отвечает на запрос к маршруту с неподдерживаемым методом
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	writeProblem(w, r, http.StatusMethodNotAllowed, entities.CodeMethodNotAllowed, "Метод не поддерживается")
+}
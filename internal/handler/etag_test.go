@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseIfMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		want    int
+		wantErr bool
+	}{
+		{name: "без заголовка", header: "", want: 0},
+		{name: "любая версия", header: "*", want: 0},
+		{name: "сильный ETag", header: `"3"`, want: 3},
+		{name: "слабый ETag", header: `W/"7"`, want: 7},
+		{name: "без кавычек", header: "5", want: 5},
+		{name: "не число", header: "abc", wantErr: true},
+		{name: "нулевая версия", header: `"0"`, wantErr: true},
+		{name: "отрицательная версия", header: `"-1"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPut, "/songs/1", nil)
+			if tt.header != "" {
+				r.Header.Set("If-Match", tt.header)
+			}
+
+			got, err := parseIfMatch(r)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseIfMatch(%q) ошибка %v, ожидалась ошибка: %v", tt.header, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseIfMatch(%q) = %d, ожидалось %d", tt.header, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"TestEffectiveMobile/internal/entities"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// exportFlushEvery через сколько песен выгрузка отправляется клиенту
+const exportFlushEvery = 100
+
+// exportColumns столбцы CSV выгрузки, совпадают с форматом импорта
+var exportColumns = []string{"id", "group", "song", "releaseDate", "releaseDatePrecision", "text", "link"}
+
+// songExporter записывает песни в ответ в одном из форматов выгрузки
+type songExporter interface {
+	// contentType тип содержимого ответа
+	contentType() string
+	// begin записывает начало выгрузки
+	begin() error
+	// write записывает одну песню
+	write(song entities.Song) error
+	// end записывает конец выгрузки
+	end() error
+}
+
+// newSongExporter возвращает запись выгрузки в формате format: csv, ndjson или json
+func newSongExporter(format string, w io.Writer, withText bool) (songExporter, error) {
+	switch format {
+	case "csv":
+		return &csvSongExporter{w: csv.NewWriter(w), withText: withText}, nil
+	case "ndjson":
+		return &jsonSongExporter{w: w, enc: json.NewEncoder(w)}, nil
+	case "", "json":
+		return &jsonSongExporter{w: w, enc: json.NewEncoder(w), array: true}, nil
+	}
+	return nil, fmt.Errorf("неизвестный формат выгрузки %q", format)
+}
+
+type csvSongExporter struct {
+	w        *csv.Writer
+	withText bool
+}
+
+func (e *csvSongExporter) contentType() string {
+	return "text/csv; charset=utf-8"
+}
+
+func (e *csvSongExporter) columns() []string {
+	if e.withText {
+		return exportColumns
+	}
+	columns := make([]string, 0, len(exportColumns)-1)
+	for _, column := range exportColumns {
+		if column != "text" {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+func (e *csvSongExporter) begin() error {
+	return e.w.Write(e.columns())
+}
+
+func (e *csvSongExporter) write(song entities.Song) error {
+	record := []string{
+		strconv.Itoa(song.ID),
+		song.Group,
+		song.Title,
+		song.ReleaseDate,
+		string(song.ReleaseDatePrecision),
+	}
+	if e.withText {
+		record = append(record, song.Text)
+	}
+	return e.w.Write(append(record, song.Link))
+}
+
+func (e *csvSongExporter) end() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// jsonSongExporter пишет песни массивом JSON или по объекту на строку (NDJSON)
+type jsonSongExporter struct {
+	w     io.Writer
+	enc   *json.Encoder
+	array bool
+	count int
+}
+
+func (e *jsonSongExporter) contentType() string {
+	if e.array {
+		return "application/json"
+	}
+	return "application/x-ndjson"
+}
+
+func (e *jsonSongExporter) begin() error {
+	if e.array {
+		_, err := io.WriteString(e.w, "[")
+		return err
+	}
+	return nil
+}
+
+func (e *jsonSongExporter) write(song entities.Song) error {
+	if e.array && e.count > 0 {
+		if _, err := io.WriteString(e.w, ","); err != nil {
+			return err
+		}
+	}
+	e.count++
+	return e.enc.Encode(song)
+}
+
+func (e *jsonSongExporter) end() error {
+	if e.array {
+		_, err := io.WriteString(e.w, "]\n")
+		return err
+	}
+	return nil
+}
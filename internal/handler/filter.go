@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"TestEffectiveMobile/internal/entities"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// filterParam параметр вида filter[поле] или filter[поле][операция]
+var filterParam = regexp.MustCompile(`^filter\[([^\]]*)\](?:\[([^\]]*)\])?$`)
+
+// parseSongFilter собирает фильтр списка песен из параметров запроса.
+// Кроме filter[поле][операция] поддерживаются прежние параметры group, song_title, match,
+// released_after, released_before и year.
+func parseSongFilter(query url.Values) (entities.SongFilter, error) {
+	var filter entities.SongFilter
+
+	match, err := entities.ParseMatchMode(query.Get("match"))
+	if err != nil {
+		return filter, err
+	}
+
+	// Способ сравнения отдельного фильтра переопределяет общий
+	for param, field := range map[string]entities.FilterField{
+		"group":      entities.FieldGroup,
+		"song_title": entities.FieldTitle,
+	} {
+		value := query.Get(param)
+		if value == "" {
+			continue
+		}
+		mode := match
+		if m := query.Get(param + "_match"); m != "" {
+			if mode, err = entities.ParseMatchMode(m); err != nil {
+				return filter, err
+			}
+		}
+		if err = filter.Add(field, mode.FilterOp(), value); err != nil {
+			return filter, err
+		}
+	}
+
+	for param, op := range map[string]entities.FilterOp{
+		"released_after":  entities.OpGte,
+		"released_before": entities.OpLte,
+	} {
+		if value := query.Get(param); value != "" {
+			if err = filter.Add(entities.FieldReleaseDate, op, value); err != nil {
+				return filter, err
+			}
+		}
+	}
+	if value := query.Get("year"); value != "" {
+		if err = filter.Add(entities.FieldReleaseYear, entities.OpEq, value); err != nil {
+			return filter, err
+		}
+	}
+
+	for param, values := range query {
+		if !strings.HasPrefix(param, "filter") {
+			continue
+		}
+		m := filterParam.FindStringSubmatch(param)
+		if m == nil {
+			return filter, fmt.Errorf("неверный параметр фильтра %q", param)
+		}
+		field, op := entities.FilterField(m[1]), entities.FilterOp(m[2])
+		if op == "" {
+			op = entities.OpEq
+		}
+
+		for _, value := range values {
+			valueOp := op
+			var args []string
+			switch op {
+			case entities.OpIn:
+				args = strings.Split(value, ",")
+			case entities.OpIsNull, entities.OpNotNull:
+				// filter[text][is_null]=false равносильно filter[text][not_null]
+				if set, err := strconv.ParseBool(value); err == nil && !set {
+					valueOp = entities.OpIsNull
+					if op == entities.OpIsNull {
+						valueOp = entities.OpNotNull
+					}
+				}
+			default:
+				args = []string{value}
+			}
+			if err = filter.Add(field, valueOp, args...); err != nil {
+				return filter, err
+			}
+		}
+	}
+	return filter, nil
+}
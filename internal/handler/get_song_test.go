@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"TestEffectiveMobile/internal/entities"
+	"TestEffectiveMobile/internal/usecase"
+	"context"
+	"github.com/gorilla/mux"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// getSongUseCase сценарий чтения одной песни
+type getSongUseCase struct {
+	usecase.SongUseCase
+	song entities.Song
+}
+
+func (u getSongUseCase) GetSongDetail(ctx context.Context, id int, includes entities.SongIncludes) (*entities.SongDetail, error) {
+	if id != u.song.ID {
+		return nil, entities.ErrNotFound
+	}
+	return &entities.SongDetail{Song: u.song}, nil
+}
+
+func TestGetSongContentLength(t *testing.T) {
+	// Текст больше буфера net/http, без явного заголовка ответ ушёл бы без Content-Length
+	song := entities.Song{ID: 1, Group: "Muse", Title: "Uprising", Text: strings.Repeat("Paranoia is in bloom\n", 500), Version: 3}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/songs/{id:[0-9]+}", NewSongHandler(getSongUseCase{song: song}).GetSong).Methods(http.MethodGet, http.MethodHead)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	get, err := http.Get(server.URL + "/songs/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(get.Body)
+	get.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if get.ContentLength != int64(len(body)) {
+		t.Errorf("GET: Content-Length %d, длина тела %d", get.ContentLength, len(body))
+	}
+
+	head, err := http.Head(server.URL + "/songs/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	head.Body.Close()
+	if got := head.Header.Get("Content-Length"); got != strconv.Itoa(len(body)) {
+		t.Errorf("HEAD: Content-Length %q, ожидалось %d", got, len(body))
+	}
+	if got, want := head.Header.Get("ETag"), get.Header.Get("ETag"); got != want {
+		t.Errorf("HEAD: ETag %q, ожидалось %q", got, want)
+	}
+}
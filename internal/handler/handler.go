@@ -4,18 +4,33 @@ import (
 	"TestEffectiveMobile/internal/entities"
 	"TestEffectiveMobile/internal/usecase"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/gorilla/mux"
+	"io"
 	"log/slog"
+	"mime"
 	"net/http"
 	"strconv"
+	"strings"
 )
 
 type SongHandler interface {
 	ListSongs(w http.ResponseWriter, r *http.Request)
 	DeleteSong(w http.ResponseWriter, r *http.Request)
 	UpdateSong(w http.ResponseWriter, r *http.Request)
+	PatchSong(w http.ResponseWriter, r *http.Request)
 	CreateSong(w http.ResponseWriter, r *http.Request)
+	ImportSongs(w http.ResponseWriter, r *http.Request)
+	ExportSongs(w http.ResponseWriter, r *http.Request)
+	ListDuplicates(w http.ResponseWriter, r *http.Request)
+	MergeSongs(w http.ResponseWriter, r *http.Request)
+	GetSong(w http.ResponseWriter, r *http.Request)
 	GetSongText(w http.ResponseWriter, r *http.Request)
+	SearchSongs(w http.ResponseWriter, r *http.Request)
+	ListTrash(w http.ResponseWriter, r *http.Request)
+	RestoreSong(w http.ResponseWriter, r *http.Request)
+	PurgeTrash(w http.ResponseWriter, r *http.Request)
 }
 
 type songHandler struct {
@@ -31,48 +46,184 @@ func NewSongHandler(useCase usecase.SongUseCase) SongHandler {
 // ListSongs godoc
 // @Summary Получение списка песен
 // @Description Получает список песен с возможностью фильтрации по группе и названию, а также с пагинацией.
+// @Description Параметр match задаёт способ сравнения для всех фильтров, group_match и song_title_match переопределяют его для отдельного фильтра.
+// @Description Без параметра sort песни упорядочены по id, а при нечётком сравнении (fuzzy) по убыванию коэффициента сходства similarity.
+// @Description Если передан параметр cursor (пустой для первой страницы), используется keyset-пагинация: ответ имеет вид {items, next_cursor}, offset игнорируется.
+// @Description Без cursor работает прежний режим limit/offset с ответом в виде массива.
+// @Description С параметром envelope=true ответ имеет вид {items, total, limit, offset, next, prev}.
+// @Description Ссылки на соседние страницы также передаются в заголовке Link (RFC 8288).
+// @Description Фильтры released_after и released_before включают границы и принимают дату в любом известном формате, в том числе неполную (2006, 2006-07).
+// @Description Произвольные условия задаются параметрами filter[поле][операция]=значение, например filter[title][contains]=love или filter[id][in]=1,2,3.
+// @Description Поля: id, artist_id, group, title, release_date, release_year, text, link. Операции: eq (по умолчанию), ne, in, gt, gte, lt, lte, is_null, not_null, prefix, contains, fuzzy.
 // @Tags songs
 // @Produce json
 // @Param group query string false "Название группы"
 // @Param song_title query string false "Название песни"
-// @Param limit query int false "Лимит записей (по умолчанию 11)"
+// @Param match query string false "Способ сравнения фильтров (по умолчанию exact)" Enums(exact, prefix, contains, fuzzy)
+// @Param group_match query string false "Способ сравнения для фильтра group" Enums(exact, prefix, contains, fuzzy)
+// @Param song_title_match query string false "Способ сравнения для фильтра song_title" Enums(exact, prefix, contains, fuzzy)
+// @Param released_after query string false "Дата выпуска не раньше"
+// @Param released_before query string false "Дата выпуска не позже"
+// @Param year query int false "Год выпуска"
+// @Param sort query string false "Сортировка через запятую по полям id, group, title, release_date, минус означает убывание (например group,-release_date,title). Песни без даты идут в конце"
+// @Param limit query int false "Лимит записей (по умолчанию 11, не больше 1000)"
 // @Param offset query int false "Сдвиг записей"
+// @Param cursor query string false "Курсор страницы из next_cursor"
+// @Param envelope query bool false "Вернуть ответ в виде объекта с общим количеством и ссылками"
 // @Success 200 {array} entities.Song "Список песен"
+// @Header 200 {string} Link "Ссылки first, prev, next, last"
+// @Failure 400 {object} entities.ErrorResponse "Неизвестное поле или неверный фильтр, сортировка, курсор или пагинация"
+// @Failure 422 {object} entities.ErrorResponse "Курсор не соответствует сортировке"
 // @Failure 500 {object} entities.ErrorResponse "Ошибка сервера"
 // @Router /songs [get]
 func (h *songHandler) ListSongs(w http.ResponseWriter, r *http.Request) {
 	const op = "internal.handler.ListSongs"
 
 	query := r.URL.Query()
-	filter := make(map[string]string)
 
-	if group := query.Get("group"); group != "" {
-		filter["group_name"] = group
+	filter, err := parseSongFilter(query)
+	if err != nil {
+		slog.Error(op, "Ошибка парсинга фильтра", slog.String("error", err.Error()))
+		writeProblem(w, r, http.StatusBadRequest, entities.CodeInvalidFilter, "Неверный фильтр: "+err.Error())
+		return
+	}
+
+	sort, err := entities.ParseSongSort(query.Get("sort"))
+	if err != nil {
+		slog.Error(op, "Ошибка парсинга параметра sort", slog.String("error", err.Error()))
+		writeProblem(w, r, http.StatusBadRequest, entities.CodeInvalidSort, "Неверная сортировка")
+		return
 	}
-	if song := query.Get("song_title"); song != "" {
-		filter["song_title"] = song
+	limit, offset, ok := parsePagination(w, r)
+	if !ok {
+		return
 	}
-	limit, _ := strconv.Atoi(query.Get("limit"))
-	if limit == 0 {
-		limit = 11
+
+	if _, ok := query["cursor"]; ok {
+		h.listSongsAfter(w, r, filter, sort, query.Get("cursor"), limit)
+		return
 	}
-	offset, _ := strconv.Atoi(query.Get("offset"))
 
-	songs, err := h.useCase.ListSongs(filter, limit, offset)
+	songs, err := h.useCase.ListSongs(r.Context(), filter, sort, limit, offset)
 	if err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		writeError(w, r, op, err)
 		return
 	}
-	json.NewEncoder(w).Encode(songs)
+
+	envelope, _ := strconv.ParseBool(query.Get("envelope"))
+	if !envelope {
+		// Без общего количества о следующей странице судим по её заполненности
+		total := -1
+		if len(songs) < limit {
+			total = offset + len(songs)
+		}
+		setPageLinks(w, newPageLinks(r, limit, offset, total))
+		json.NewEncoder(w).Encode(songs)
+		return
+	}
+
+	total, err := h.useCase.CountSongs(r.Context(), filter)
+	if err != nil {
+		writeError(w, r, op, err)
+		return
+	}
+	links := newPageLinks(r, limit, offset, total)
+	setPageLinks(w, links)
+	json.NewEncoder(w).Encode(entities.SongPage{
+		Items:  songs,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+		Next:   links.next,
+		Prev:   links.prev,
+	})
+}
+
+// listSongsAfter отдаёт страницу песен в режиме keyset-пагинации
+func (h *songHandler) listSongsAfter(
+	w http.ResponseWriter,
+	r *http.Request,
+	filter entities.SongFilter,
+	sort entities.SongSort,
+	token string,
+	limit int,
+) {
+	const op = "internal.handler.listSongsAfter"
+
+	var (
+		cursor *entities.Cursor
+		err    error
+	)
+	if token != "" {
+		if cursor, err = entities.DecodeCursor(token); err != nil {
+			slog.Error(op, "Ошибка парсинга курсора", slog.String("error", err.Error()))
+			writeProblem(w, r, http.StatusBadRequest, entities.CodeInvalidCursor, "Неверный курсор")
+			return
+		}
+	}
+
+	songs, next, err := h.useCase.ListSongsAfter(r.Context(), filter, sort, cursor, limit)
+	if err != nil {
+		writeError(w, r, op, err)
+		return
+	}
+
+	page := entities.SongCursorPage{Items: songs}
+	if next != nil {
+		page.NextCursor = next.Encode()
+		setPageLinks(w, pageLinks{
+			first: pageURL(r, map[string]string{"cursor": ""}),
+			next:  pageURL(r, map[string]string{"cursor": page.NextCursor}),
+		})
+	}
+	json.NewEncoder(w).Encode(page)
+}
+
+// SearchSongs godoc
+// @Summary Полнотекстовый поиск песен
+// @Description Ищет песни по названию, группе и тексту. Результаты упорядочены по релевантности, содержат фрагмент текста с подсветкой и номера совпавших куплетов.
+// @Tags songs
+// @Produce json
+// @Param q query string true "Поисковый запрос"
+// @Param limit query int false "Лимит записей (по умолчанию 11, не больше 1000)"
+// @Param offset query int false "Сдвиг записей"
+// @Success 200 {array} entities.SongSearchResult "Найденные песни"
+// @Failure 400 {object} entities.ErrorResponse "Пустой запрос или неверные параметры пагинации"
+// @Failure 500 {object} entities.ErrorResponse "Ошибка сервера"
+// @Router /songs/search [get]
+func (h *songHandler) SearchSongs(w http.ResponseWriter, r *http.Request) {
+	const op = "internal.handler.SearchSongs"
+
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if q == "" {
+		writeProblem(w, r, http.StatusBadRequest, entities.CodeInvalidParameter, "Пустой поисковый запрос",
+			entities.FieldError{Field: "q", Message: "обязательный параметр"})
+		return
+	}
+	limit, offset, ok := parsePagination(w, r)
+	if !ok {
+		return
+	}
+
+	results, err := h.useCase.SearchSongs(r.Context(), q, limit, offset)
+	if err != nil {
+		writeError(w, r, op, err)
+		return
+	}
+	json.NewEncoder(w).Encode(results)
 }
 
 // DeleteSong godoc
 // @Summary Удаление песни
-// @Description Удаляет песню по идентификатору.
+// @Description Перемещает песню в корзину. Песню можно восстановить, пока она не удалена окончательно очисткой корзины.
+// @Description Если передан заголовок If-Match, песня удаляется только при совпадении версии.
 // @Tags songs
 // @Param id path int true "ID песни"
+// @Param If-Match header string false "ETag песни, полученный при чтении"
 // @Success 204 {string} string "No Content"
-// @Failure 400 {object} entities.ErrorResponse "Неверный ID"
+// @Failure 400 {object} entities.ErrorResponse "Неверный ID или If-Match"
+// @Failure 404 {object} entities.ErrorResponse "Песня не найдена"
+// @Failure 412 {object} entities.ErrorResponse "Версия песни изменилась"
 // @Failure 500 {object} entities.ErrorResponse "Internal Server Error"
 // @Router /songs/{id} [delete]
 func (h *songHandler) DeleteSong(w http.ResponseWriter, r *http.Request) {
@@ -82,28 +233,117 @@ func (h *songHandler) DeleteSong(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
 		slog.Error(op, "Ошибка парсинга id с параметров", slog.String("error", err.Error()))
-		http.Error(w, "Неверный ID", http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, entities.CodeInvalidID, "Неверный ID")
+		return
+	}
+
+	version, err := parseIfMatch(r)
+	if err != nil {
+		slog.Error(op, "Ошибка парсинга If-Match", slog.String("error", err.Error()))
+		writeProblem(w, r, http.StatusBadRequest, entities.CodeInvalidIfMatch, err.Error())
+		return
+	}
+
+	if err = h.useCase.DeleteSong(r.Context(), id, version); err != nil {
+		writeError(w, r, op, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListTrash godoc
+// @Summary Получение песен из корзины
+// @Description Возвращает удалённые песни, начиная с удалённых последними.
+// @Tags trash
+// @Produce json
+// @Param limit query int false "Лимит записей (по умолчанию 11, не больше 1000)"
+// @Param offset query int false "Сдвиг записей"
+// @Success 200 {array} entities.Song "Список песен в корзине"
+// @Failure 400 {object} entities.ErrorResponse "Неверные параметры пагинации"
+// @Failure 500 {object} entities.ErrorResponse "Internal Server Error"
+// @Router /trash/songs [get]
+func (h *songHandler) ListTrash(w http.ResponseWriter, r *http.Request) {
+	const op = "internal.handler.ListTrash"
+
+	limit, offset, ok := parsePagination(w, r)
+	if !ok {
+		return
+	}
+
+	songs, err := h.useCase.ListTrash(r.Context(), limit, offset)
+	if err != nil {
+		writeError(w, r, op, err)
+		return
+	}
+	json.NewEncoder(w).Encode(songs)
+}
+
+// RestoreSong godoc
+// @Summary Восстановление песни из корзины
+// @Description Возвращает песню из корзины в общий список.
+// @Tags trash
+// @Param id path int true "ID песни"
+// @Success 204 {string} string "No Content"
+// @Failure 400 {object} entities.ErrorResponse "Неверный ID"
+// @Failure 404 {object} entities.ErrorResponse "Песня не найдена в корзине"
+// @Failure 409 {object} entities.ErrorResponse "В библиотеке уже есть такая песня"
+// @Failure 500 {object} entities.ErrorResponse "Internal Server Error"
+// @Router /songs/{id}/restore [post]
+func (h *songHandler) RestoreSong(w http.ResponseWriter, r *http.Request) {
+	const op = "internal.handler.RestoreSong"
+
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		slog.Error(op, "Ошибка парсинга id с параметров", slog.String("error", err.Error()))
+		writeProblem(w, r, http.StatusBadRequest, entities.CodeInvalidID, "Неверный ID")
 		return
 	}
 
-	if err = h.useCase.DeleteSong(id); err != nil {
-		slog.Error(op, "Ошибка при удаления песни", slog.String("error", err.Error()))
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	if err = h.useCase.RestoreSong(r.Context(), id); err != nil {
+		writeError(w, r, op, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// PurgeTrash godoc
+// @Summary Очистка корзины
+// @Description Окончательно удаляет песни, которые находятся в корзине дольше срока хранения (TRASH_RETENTION).
+// @Tags admin
+// @Produce json
+// @Success 200 {object} map[string]int64 "Количество удалённых песен"
+// @Failure 500 {object} entities.ErrorResponse "Internal Server Error"
+// @Router /admin/trash/purge [post]
+func (h *songHandler) PurgeTrash(w http.ResponseWriter, r *http.Request) {
+	const op = "internal.handler.PurgeTrash"
+
+	purged, err := h.useCase.PurgeTrash(r.Context())
+	if err != nil {
+		writeError(w, r, op, err)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]int64{"purged": purged})
+}
+
 // UpdateSong godoc
 // @Summary Обновление данных песни
 // @Description Обновляет данные песни по идентификатору. Передаётся JSON объект песни.
+// @Description Если передан заголовок If-Match, песня обновляется только при совпадении версии.
+// @Description Неизвестные поля запрещены. Все нарушения правил полей (обязательность, длина, формат ссылки и даты) возвращаются сразу в details.
 // @Tags songs
 // @Accept json
 // @Produce json
 // @Param id path int true "ID песни"
+// @Param If-Match header string false "ETag песни, полученный при чтении"
 // @Param song body entities.Song true "Обновленные данные песни"
 // @Success 200 {string} string "OK"
-// @Failure 400 {object} entities.ErrorResponse "Неверный ID или Bad Request"
+// @Failure 400 {object} entities.ErrorResponse "Неверный ID, If-Match или Bad Request"
+// @Failure 404 {object} entities.ErrorResponse "Песня не найдена"
+// @Failure 409 {object} entities.ErrorResponse "Песня с такими группой и названием уже существует"
+// @Failure 412 {object} entities.ErrorResponse "Версия песни изменилась"
+// @Failure 413 {object} entities.ErrorResponse "Тело запроса слишком большое"
+// @Failure 422 {object} entities.ErrorResponse "Поля песни не прошли проверку, список в details"
 // @Failure 500 {object} entities.ErrorResponse "Internal Server Error"
 // @Router /songs/{id} [put]
 func (h *songHandler) UpdateSong(w http.ResponseWriter, r *http.Request) {
@@ -113,56 +353,490 @@ func (h *songHandler) UpdateSong(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
 		slog.Error(op, "Ошибка парсинга id с параметров", slog.String("error", err.Error()))
-		http.Error(w, "Неверный ID", http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, entities.CodeInvalidID, "Неверный ID")
 		return
 	}
 
 	var song entities.Song
-	if err = json.NewDecoder(r.Body).Decode(&song); err != nil {
+	if err = decodeJSON(w, r, &song); err != nil {
 		slog.Error(op, "Ошибка декодинга данных", slog.String("error", err.Error()))
-		http.Error(w, "Bad Request", http.StatusBadRequest)
+		writeDecodeError(w, r, err)
 		return
 	}
 	song.ID = id
-	if err = h.useCase.UpdateSong(song); err != nil {
-		slog.Error(op, "Ошибка обновления данных песни", slog.String("error", err.Error()))
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	if song.Version, err = parseIfMatch(r); err != nil {
+		slog.Error(op, "Ошибка парсинга If-Match", slog.String("error", err.Error()))
+		writeProblem(w, r, http.StatusBadRequest, entities.CodeInvalidIfMatch, err.Error())
+		return
+	}
+	if err = h.useCase.UpdateSong(r.Context(), song); err != nil {
+		writeError(w, r, op, err)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
 }
 
+// PatchSong godoc
+// @Summary Частичное обновление песни
+// @Description Изменяет только переданные поля песни. Принимает JSON Merge Patch (application/merge-patch+json или application/json)
+// @Description и JSON Patch (application/json-patch+json) с операциями add, replace, remove и test.
+// @Description null в Merge Patch и remove в JSON Patch очищают необязательные поля, group и song очистить нельзя.
+// @Tags songs
+// @Accept application/merge-patch+json,application/json-patch+json,json
+// @Produce json
+// @Param id path int true "ID песни"
+// @Param If-Match header string false "ETag песни, полученный при чтении"
+// @Param patch body entities.SongPatch true "Изменяемые поля песни"
+// @Success 200 {object} entities.Song "Обновленная песня"
+// @Header 200 {string} ETag "Новая версия песни"
+// @Failure 400 {object} entities.ErrorResponse "Неверный ID, If-Match или Bad Request"
+// @Failure 404 {object} entities.ErrorResponse "Песня не найдена"
+// @Failure 409 {object} entities.ErrorResponse "Проверка test не пройдена"
+// @Failure 409 {object} entities.ErrorResponse "Песня с такими группой и названием уже существует"
+// @Failure 412 {object} entities.ErrorResponse "Версия песни изменилась"
+// @Failure 413 {object} entities.ErrorResponse "Тело запроса слишком большое"
+// @Failure 415 {object} entities.ErrorResponse "Неподдерживаемый формат патча"
+// @Failure 422 {object} entities.ErrorResponse "Поля песни не прошли проверку, список в details"
+// @Failure 500 {object} entities.ErrorResponse "Internal Server Error"
+// @Router /songs/{id} [patch]
+func (h *songHandler) PatchSong(w http.ResponseWriter, r *http.Request) {
+	const op = "internal.handler.PatchSong"
+
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		slog.Error(op, "Ошибка парсинга id с параметров", slog.String("error", err.Error()))
+		writeProblem(w, r, http.StatusBadRequest, entities.CodeInvalidID, "Неверный ID")
+		return
+	}
+	version, err := parseIfMatch(r)
+	if err != nil {
+		slog.Error(op, "Ошибка парсинга If-Match", slog.String("error", err.Error()))
+		writeProblem(w, r, http.StatusBadRequest, entities.CodeInvalidIfMatch, err.Error())
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		slog.Error(op, "Ошибка чтения тела запроса", slog.String("error", err.Error()))
+		writeDecodeError(w, r, err)
+		return
+	}
+
+	var patch entities.SongPatch
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/merge-patch+json", "application/json":
+		patch, err = decodeMergePatch(body)
+	case "application/json-patch+json":
+		// Операции test сверяются с текущей версией, поэтому патч применяется только к ней
+		song, getErr := h.useCase.GetSongByID(r.Context(), id)
+		if getErr != nil {
+			writeError(w, r, op, getErr)
+			return
+		}
+		if version == 0 {
+			version = song.Version
+		}
+		patch, err = decodeJSONPatch(body, song)
+	default:
+		writeProblem(w, r, http.StatusUnsupportedMediaType, entities.CodeUnsupportedMediaType, "Неподдерживаемый формат патча")
+		return
+	}
+	if err != nil {
+		slog.Error(op, "Ошибка разбора патча", slog.String("error", err.Error()))
+		if errors.Is(err, errPatchTestFailed) {
+			writeProblem(w, r, http.StatusConflict, entities.CodePatchTestFailed, "Проверка test не пройдена")
+			return
+		}
+		writeProblem(w, r, http.StatusBadRequest, entities.CodeInvalidBody, "Bad Request")
+		return
+	}
+
+	if err = h.useCase.PatchSong(r.Context(), id, patch, version); err != nil {
+		writeError(w, r, op, err)
+		return
+	}
+
+	song, err := h.useCase.GetSongByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, op, err)
+		return
+	}
+	w.Header().Set("ETag", formatETag(song.Version))
+	json.NewEncoder(w).Encode(song)
+}
+
 // CreateSong godoc
 // @Summary Добавление новой песни
-// @Description Создает новую песню, выполняет вызов внешнего API для обогащения данных и сохраняет в базу.
+// @Description Сохраняет новую песню вместе с переданными releaseDate, text и link. Если какое-то из этих полей не заполнено,
+// @Description песня получает статус обогащения pending и ставится в очередь, недостающие данные заполняет внешний API.
+// @Description Ход обогащения показывает GET /songs/{id}/enrichment.
+// @Description Группа и название сравниваются без учёта регистра и пробелов по краям. Если такая песня уже есть, возвращается 409 с её ID,
+// @Description а с параметром onConflict=update существующая песня ставится в очередь на обновление данными из внешнего API и возвращается со статусом 200.
+// @Description Неизвестные поля запрещены. Все нарушения правил полей (обязательность, длина, формат ссылки и даты) возвращаются сразу в details.
 // @Tags songs
 // @Accept json
 // @Produce json
 // @Param song body entities.Song true "Данные новой песни"
+// @Param onConflict query string false "Действие, если песня уже существует (по умолчанию error)" Enums(error, update)
+// @Success 200 {object} entities.Song "Существующая песня, поставленная в очередь обогащения"
 // @Success 201 {object} entities.Song "Созданная песня"
+// @Header 201 {string} Location "Адрес созданной песни"
 // @Failure 400 {object} entities.ErrorResponse "Bad Request"
+// @Failure 409 {object} entities.ErrorResponse "Песня уже существует, её ID в поле existingId"
+// @Failure 413 {object} entities.ErrorResponse "Тело запроса слишком большое"
+// @Failure 422 {object} entities.ErrorResponse "Поля песни не прошли проверку, список в details"
 // @Failure 500 {object} entities.ErrorResponse "Internal Server Error"
 // @Router /songs [post]
 func (h *songHandler) CreateSong(w http.ResponseWriter, r *http.Request) {
 	const op = "internal.handler.CreateSong"
 
+	var upsert bool
+	switch r.URL.Query().Get("onConflict") {
+	case "", "error":
+	case "update":
+		upsert = true
+	default:
+		writeProblem(w, r, http.StatusBadRequest, entities.CodeInvalidParameter, "Неверное значение onConflict",
+			entities.FieldError{Field: "onConflict", Message: "допустимые значения: error, update"})
+		return
+	}
+
 	var song entities.Song
-	if err := json.NewDecoder(r.Body).Decode(&song); err != nil {
+	if err := decodeJSON(w, r, &song); err != nil {
 		slog.Error(op, "Ошибка декодинга данных", slog.String("error", err.Error()))
-		http.Error(w, "Bad Request", http.StatusBadRequest)
+		writeDecodeError(w, r, err)
 		return
 	}
 
-	id, err := h.useCase.CreateSong(song)
+	id, created, err := h.useCase.CreateSong(r.Context(), song, upsert)
 	if err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		writeError(w, r, op, err)
 		return
 	}
-	song.ID = id
-	w.WriteHeader(http.StatusCreated)
+
+	stored, err := h.useCase.GetSongByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, op, err)
+		return
+	}
+	w.Header().Set("ETag", formatETag(stored.Version))
+	if created {
+		w.Header().Set("Location", fmt.Sprintf("/songs/%d", id))
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(stored)
+}
+
+// defaultDuplicateThreshold порог сходства отчёта о дубликатах по умолчанию
+const defaultDuplicateThreshold = 0.5
+
+// ListDuplicates godoc
+// @Summary Отчёт о похожих песнях
+// @Description Находит пары песен, у которых похожи группа и название (например, отличаются опечаткой или знаками препинания).
+// @Description Сходство считается по триграммам от 0 до 1. Порогу threshold должны соответствовать и группы, и названия,
+// @Description сходство пары - среднее этих двух значений, пары упорядочены по его убыванию.
+// @Tags songs
+// @Produce json
+// @Param threshold query number false "Минимальное сходство групп и названий от 0 до 1 (по умолчанию 0.5)"
+// @Param limit query int false "Лимит записей (по умолчанию 11, не больше 1000)"
+// @Param offset query int false "Сдвиг записей"
+// @Success 200 {array} entities.SongDuplicate "Пары похожих песен"
+// @Failure 400 {object} entities.ErrorResponse "Неверный порог или параметры пагинации"
+// @Failure 500 {object} entities.ErrorResponse "Internal Server Error"
+// @Router /songs/duplicates [get]
+func (h *songHandler) ListDuplicates(w http.ResponseWriter, r *http.Request) {
+	const op = "internal.handler.ListDuplicates"
+
+	threshold := defaultDuplicateThreshold
+	if value := r.URL.Query().Get("threshold"); value != "" {
+		var err error
+		threshold, err = strconv.ParseFloat(value, 64)
+		if err != nil || threshold <= 0 || threshold > 1 {
+			writeProblem(w, r, http.StatusBadRequest, entities.CodeInvalidParameter, "Неверный порог",
+				entities.FieldError{Field: "threshold", Message: "ожидается число от 0 до 1"})
+			return
+		}
+	}
+	limit, offset, ok := parsePagination(w, r)
+	if !ok {
+		return
+	}
+
+	duplicates, err := h.useCase.ListDuplicates(r.Context(), threshold, limit, offset)
+	if err != nil {
+		writeError(w, r, op, err)
+		return
+	}
+	json.NewEncoder(w).Encode(duplicates)
+}
+
+// MergeSongs godoc
+// @Summary Объединение песен
+// @Description Объединяет песню sourceId с песней из пути запроса: дата выпуска, текст и ссылка sourceId заполняют пустые поля песни,
+// @Description а сама sourceId перемещается в корзину. Если передан заголовок If-Match, проверяется версия песни из пути запроса.
+// @Tags songs
+// @Accept json
+// @Produce json
+// @Param id path int true "ID песни, которая остаётся в библиотеке"
+// @Param If-Match header string false "ETag песни, полученный при чтении"
+// @Param request body entities.SongMergeRequest true "Песня, которая объединяется"
+// @Success 200 {object} entities.Song "Объединённая песня"
+// @Failure 400 {object} entities.ErrorResponse "Неверный ID, If-Match или Bad Request"
+// @Failure 404 {object} entities.ErrorResponse "Песня не найдена"
+// @Failure 412 {object} entities.ErrorResponse "Версия песни изменилась"
+// @Failure 413 {object} entities.ErrorResponse "Тело запроса слишком большое"
+// @Failure 500 {object} entities.ErrorResponse "Internal Server Error"
+// @Router /songs/{id}/merge [post]
+func (h *songHandler) MergeSongs(w http.ResponseWriter, r *http.Request) {
+	const op = "internal.handler.MergeSongs"
+
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		slog.Error(op, "Ошибка парсинга id с параметров", slog.String("error", err.Error()))
+		writeProblem(w, r, http.StatusBadRequest, entities.CodeInvalidID, "Неверный ID")
+		return
+	}
+
+	var req entities.SongMergeRequest
+	if err = decodeJSON(w, r, &req); err != nil {
+		slog.Error(op, "Ошибка декодинга данных", slog.String("error", err.Error()))
+		writeDecodeError(w, r, err)
+		return
+	}
+	if req.SourceID <= 0 || req.SourceID == id {
+		writeProblem(w, r, http.StatusBadRequest, entities.CodeInvalidParameter, "Неверный sourceId",
+			entities.FieldError{Field: "sourceId", Message: "ожидается ID другой существующей песни"})
+		return
+	}
+
+	version, err := parseIfMatch(r)
+	if err != nil {
+		slog.Error(op, "Ошибка парсинга If-Match", slog.String("error", err.Error()))
+		writeProblem(w, r, http.StatusBadRequest, entities.CodeInvalidIfMatch, err.Error())
+		return
+	}
+	if err = h.useCase.MergeSongs(r.Context(), id, req.SourceID, version); err != nil {
+		writeError(w, r, op, err)
+		return
+	}
+
+	song, err := h.useCase.GetSongByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, op, err)
+		return
+	}
+	w.Header().Set("ETag", formatETag(song.Version))
 	json.NewEncoder(w).Encode(song)
 }
 
+// ImportSongs godoc
+// @Summary Массовый импорт песен
+// @Description Импортирует песни из файла CSV (с заголовком group,song[,releaseDate,text,link]) или NDJSON (по объекту с полями group, song, releaseDate, text, link на строку, строка с другими полями попадает в отчёт как failed).
+// @Description Файл передаётся телом запроса или полем file формы multipart/form-data, формат определяется по Content-Type, расширению файла или параметру format.
+// @Description Песни с незаполненными releaseDate, text или link сохраняются со статусом обогащения pending, недостающие данные заполняет очередь обогащения.
+// @Description Песни, которые уже есть в библиотеке или повторяются в файле, пропускаются. В режиме dry_run файл только проверяется и в DB ничего не записывается.
+// @Tags songs
+// @Accept text/csv,application/x-ndjson,mpfd
+// @Produce json
+// @Param format query string false "Формат файла" Enums(csv, ndjson)
+// @Param dry_run query bool false "Только проверить файл"
+// @Param file formData file false "Файл импорта"
+// @Success 200 {object} entities.ImportReport "Отчёт об импорте"
+// @Failure 400 {object} entities.ErrorResponse "Неверный файл"
+// @Failure 413 {object} entities.ErrorResponse "Файл слишком большой"
+// @Router /songs/import [post]
+func (h *songHandler) ImportSongs(w http.ResponseWriter, r *http.Request) {
+	const op = "internal.handler.ImportSongs"
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+
+	body, format, err := readImportFile(r)
+	if err != nil {
+		slog.Error(op, "Ошибка чтения файла импорта", slog.String("error", err.Error()))
+		writeImportReadError(w, r, err)
+		return
+	}
+
+	var rows []entities.ImportRow
+	if format == "csv" {
+		rows, err = parseImportCSV(body)
+	} else {
+		rows, err = parseImportNDJSON(body)
+	}
+	if err != nil {
+		slog.Error(op, "Ошибка разбора файла импорта", slog.String("error", err.Error()))
+		writeImportReadError(w, r, err)
+		return
+	}
+
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry_run"))
+	json.NewEncoder(w).Encode(h.useCase.ImportSongs(r.Context(), rows, dryRun))
+}
+
+// writeImportReadError отвечает на ошибку чтения файла импорта
+func writeImportReadError(w http.ResponseWriter, r *http.Request, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		writeProblem(w, r, http.StatusRequestEntityTooLarge, entities.CodePayloadTooLarge, "Файл слишком большой")
+		return
+	}
+	writeProblem(w, r, http.StatusBadRequest, entities.CodeInvalidFile, "Неверный файл: "+err.Error())
+}
+
+// ExportSongs godoc
+// @Summary Выгрузка библиотеки песен
+// @Description Выгружает все песни, подходящие под фильтры, в формате CSV, NDJSON или JSON.
+// @Description Песни читаются из курсора DB и передаются клиенту по мере чтения, без загрузки всей библиотеки в память.
+// @Description Поддерживаются те же фильтры и сортировка, что и у списка песен (GET /songs). CSV выгрузку можно загрузить обратно через POST /songs/import.
+// @Tags songs
+// @Produce json,text/csv,application/x-ndjson
+// @Param format query string false "Формат выгрузки (по умолчанию json)" Enums(csv, ndjson, json)
+// @Param omit_text query bool false "Не выгружать тексты песен"
+// @Param group query string false "Название группы"
+// @Param song_title query string false "Название песни"
+// @Param match query string false "Способ сравнения фильтров (по умолчанию exact)" Enums(exact, prefix, contains, fuzzy)
+// @Param released_after query string false "Дата выпуска не раньше"
+// @Param released_before query string false "Дата выпуска не позже"
+// @Param year query int false "Год выпуска"
+// @Param sort query string false "Сортировка через запятую по полям id, group, title, release_date"
+// @Success 200 {array} entities.Song "Песни"
+// @Failure 400 {object} entities.ErrorResponse "Неверный формат, фильтр или сортировка"
+// @Failure 500 {object} entities.ErrorResponse "Ошибка сервера"
+// @Router /songs/export [get]
+func (h *songHandler) ExportSongs(w http.ResponseWriter, r *http.Request) {
+	const op = "internal.handler.ExportSongs"
+
+	query := r.URL.Query()
+
+	filter, err := parseSongFilter(query)
+	if err != nil {
+		slog.Error(op, "Ошибка парсинга фильтра", slog.String("error", err.Error()))
+		writeProblem(w, r, http.StatusBadRequest, entities.CodeInvalidFilter, "Неверный фильтр: "+err.Error())
+		return
+	}
+
+	sort, err := entities.ParseSongSort(query.Get("sort"))
+	if err != nil {
+		slog.Error(op, "Ошибка парсинга параметра sort", slog.String("error", err.Error()))
+		writeProblem(w, r, http.StatusBadRequest, entities.CodeInvalidSort, "Неверная сортировка")
+		return
+	}
+
+	omitText, _ := strconv.ParseBool(query.Get("omit_text"))
+	format := strings.ToLower(query.Get("format"))
+	exporter, err := newSongExporter(format, w, !omitText)
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, entities.CodeInvalidParameter, err.Error(),
+			entities.FieldError{Field: "format", Message: "допустимые значения: csv, ndjson, json"})
+		return
+	}
+
+	// Заголовки отправляются с первой песней, чтобы ошибку запроса к DB можно было вернуть статусом 500
+	started := false
+	start := func() error {
+		started = true
+		if format == "" {
+			format = "json"
+		}
+		w.Header().Set("Content-Type", exporter.contentType())
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="songs.%s"`, format))
+		return exporter.begin()
+	}
+	flusher, _ := w.(http.Flusher)
+	count := 0
+
+	err = h.useCase.ExportSongs(r.Context(), filter, sort, !omitText, func(song entities.Song) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		if err := exporter.write(song); err != nil {
+			return err
+		}
+		count++
+		if flusher != nil && count%exportFlushEvery == 0 {
+			flusher.Flush()
+		}
+		return nil
+	})
+	if err == nil && !started {
+		err = start()
+	}
+	if err == nil {
+		err = exporter.end()
+	}
+	if err != nil {
+		if !started {
+			writeError(w, r, op, err)
+			return
+		}
+		// Ответ уже начат, поэтому клиент узнает об ошибке только по оборванной выгрузке
+		slog.Error(op, "Ошибка выгрузки песен", slog.String("error", err.Error()), slog.Int("count", count))
+		return
+	}
+	slog.Info(op+": выгрузка завершена", "count", count, "format", format)
+}
+
+// GetSong godoc
+// @Summary Получение песни
+// @Description Возвращает песню целиком. Параметр include через запятую встраивает связанные данные:
+// @Description verses - количество куплетов, revisions - количество ревизий, artist - исполнитель с количеством его песен.
+// @Description HEAD возвращает те же заголовки (ETag, Content-Length) без тела.
+// @Tags songs
+// @Produce json
+// @Param id path int true "ID песни"
+// @Param include query string false "Связанные данные через запятую: verses, revisions, artist"
+// @Success 200 {object} entities.SongDetail "Песня"
+// @Header 200 {string} ETag "Версия песни"
+// @Header 200 {integer} Content-Length "Длина тела ответа, в том числе для HEAD"
+// @Failure 400 {object} entities.ErrorResponse "Неверный ID или include"
+// @Failure 404 {object} entities.ErrorResponse "Песня не найдена"
+// @Failure 500 {object} entities.ErrorResponse "Internal Server Error"
+// @Router /songs/{id} [get]
+// @Router /songs/{id} [head]
+func (h *songHandler) GetSong(w http.ResponseWriter, r *http.Request) {
+	const op = "internal.handler.GetSong"
+
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		slog.Error(op, "Ошибка парсинга id с параметров", slog.String("error", err.Error()))
+		writeProblem(w, r, http.StatusBadRequest, entities.CodeInvalidID, "Неверный ID")
+		return
+	}
+
+	includes, err := entities.ParseSongIncludes(r.URL.Query().Get("include"))
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, entities.CodeInvalidParameter, "Неверный параметр include",
+			entities.FieldError{Field: "include", Message: err.Error()})
+		return
+	}
+
+	song, err := h.useCase.GetSongDetail(r.Context(), id, includes)
+	if err != nil {
+		writeError(w, r, op, err)
+		return
+	}
+
+	// net/http сам выставляет Content-Length только для небольших ответов,
+	// поэтому тело собирается заранее: HEAD получает ту же длину, что и GET
+	body, err := json.Marshal(song)
+	if err != nil {
+		writeError(w, r, op, err)
+		return
+	}
+	body = append(body, '\n')
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.Header().Set("ETag", formatETag(song.Version))
+	w.Write(body)
+}
+
 // GetSongText godoc
 // @Summary Получение текста песни с пагинацией куплетов
 // @Description Возвращает текст песни, разделенный на куплеты с пагинацией. Параметры versePage и versePageSize управляют выводом куплетов.
@@ -172,8 +846,10 @@ func (h *songHandler) CreateSong(w http.ResponseWriter, r *http.Request) {
 // @Param versePage query int false "Номер страницы куплетов (по умолчанию 1)"
 // @Param versePageSize query int false "Количество куплетов на странице (по умолчанию 5)"
 // @Success 200 {string} string "Текст песни"
+// @Header 200 {string} ETag "Версия песни"
 // @Failure 400 {object} entities.ErrorResponse "Неверный ID"
 // @Failure 404 {object} entities.ErrorResponse "Песня не найдена"
+// @Failure 422 {object} entities.ErrorResponse "Неверная страница куплетов"
 // @Failure 500 {object} entities.ErrorResponse "Internal Server Error"
 // @Router /songs/{id}/text [get]
 func (h *songHandler) GetSongText(w http.ResponseWriter, r *http.Request) {
@@ -183,13 +859,13 @@ func (h *songHandler) GetSongText(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
 		slog.Error(op, "Ошибка парсинга id с параметров", slog.String("error", err.Error()))
-		http.Error(w, "Неверный ID", http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, entities.CodeInvalidID, "Неверный ID")
 		return
 	}
 
-	song, err := h.useCase.GetSongByID(id)
+	song, err := h.useCase.GetSongByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, "Песня не найдена", http.StatusNotFound)
+		writeError(w, r, op, err)
 		return
 	}
 
@@ -205,9 +881,9 @@ func (h *songHandler) GetSongText(w http.ResponseWriter, r *http.Request) {
 
 	text, err := h.useCase.GetSongText(song, versePage, versePageSize)
 	if err != nil {
-		slog.Error(op, "Ошибка получения куплетов", slog.String("error", err.Error()))
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		writeError(w, r, op, err)
 		return
 	}
+	w.Header().Set("ETag", formatETag(song.Version))
 	w.Write([]byte(text))
 }
@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"TestEffectiveMobile/internal/entities"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// maxImportSize максимальный размер загружаемого файла импорта
+const maxImportSize = 10 << 20
+
+// importColumns допустимые названия столбцов CSV и соответствующие поля песни
+var importColumns = map[string]string{
+	"group":        "group",
+	"song":         "song",
+	"title":        "song",
+	"releasedate":  "releaseDate",
+	"release_date": "releaseDate",
+	"text":         "text",
+	"link":         "link",
+}
+
+// readImportFile возвращает содержимое файла импорта и его формат (csv или ndjson).
+// Файл передаётся телом запроса либо полем file формы multipart/form-data.
+func readImportFile(r *http.Request) (io.Reader, string, error) {
+	format := strings.ToLower(r.URL.Query().Get("format"))
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	body := io.Reader(r.Body)
+	if mediaType == "multipart/form-data" {
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			return nil, "", fmt.Errorf("поле file не найдено: %w", err)
+		}
+		body = file
+		if format == "" {
+			switch strings.ToLower(filepath.Ext(header.Filename)) {
+			case ".csv":
+				format = "csv"
+			case ".ndjson", ".jsonl":
+				format = "ndjson"
+			}
+		}
+		mediaType, _, _ = mime.ParseMediaType(header.Header.Get("Content-Type"))
+	}
+
+	if format == "" {
+		switch mediaType {
+		case "text/csv":
+			format = "csv"
+		case "application/x-ndjson", "application/ndjson", "application/jsonl":
+			format = "ndjson"
+		}
+	}
+	if format != "csv" && format != "ndjson" {
+		return nil, "", errors.New("не удалось определить формат файла, укажите format=csv или format=ndjson")
+	}
+	return body, format, nil
+}
+
+// parseImportCSV разбирает CSV с заголовком. Обязательные столбцы: group и song (или title).
+func parseImportCSV(body io.Reader) ([]entities.ImportRow, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения заголовка CSV: %w", err)
+	}
+	columns := make([]string, len(header))
+	for i, name := range header {
+		columns[i] = importColumns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\uFEFF")))]
+	}
+
+	rows := make([]entities.ImportRow, 0)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		var row entities.ImportRow
+		var parseErr *csv.ParseError
+		switch {
+		case errors.As(err, &parseErr):
+			// FieldPos после ошибки разбора недоступен, номер строки берём из ошибки
+			row.Line = parseErr.StartLine
+			row.Err = parseErr
+		case err != nil:
+			return nil, fmt.Errorf("ошибка чтения CSV: %w", err)
+		default:
+			row.Line, _ = reader.FieldPos(0)
+			song := make(map[string]string, len(record))
+			for i, value := range record {
+				if i < len(columns) && columns[i] != "" {
+					song[columns[i]] = value
+				}
+			}
+			row.Song = entities.Song{
+				Group:       song["group"],
+				Title:       song["song"],
+				ReleaseDate: song["releaseDate"],
+				Text:        song["text"],
+				Link:        song["link"],
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// importSongRequest строка NDJSON файла импорта. Служебные поля песни (id, version,
+// enrichmentStatus) задаёт сервер, поэтому в строке они считаются неизвестными.
+type importSongRequest struct {
+	Group       string `json:"group"`
+	Title       string `json:"song"`
+	ReleaseDate string `json:"releaseDate"`
+	Text        string `json:"text"`
+	Link        string `json:"link"`
+}
+
+// parseImportNDJSON разбирает файл, где каждая строка - JSON объект песни
+func parseImportNDJSON(body io.Reader) ([]entities.ImportRow, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxImportSize)
+
+	rows := make([]entities.ImportRow, 0)
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		row := entities.ImportRow{Line: line}
+		song, err := decodeImportLine(data)
+		if err != nil {
+			row.Err = fmt.Errorf("неверный JSON: %w", err)
+		} else {
+			row.Song = entities.Song{
+				Group:       song.Group,
+				Title:       song.Title,
+				ReleaseDate: song.ReleaseDate,
+				Text:        song.Text,
+				Link:        song.Link,
+			}
+		}
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения NDJSON: %w", err)
+	}
+	return rows, nil
+}
+
+// decodeImportLine разбирает одну строку NDJSON так же строго, как decodeJSON тело запроса
+func decodeImportLine(data []byte) (importSongRequest, error) {
+	var song importSongRequest
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&song); err != nil {
+		return song, err
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return song, errors.New("после JSON-объекта есть лишние данные")
+	}
+	return song, nil
+}
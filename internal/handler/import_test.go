@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"TestEffectiveMobile/internal/entities"
+	"TestEffectiveMobile/internal/usecase"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// importSongUseCase сценарий импорта, который отвечает отчётом по разобранным обработчиком строкам
+type importSongUseCase struct {
+	usecase.SongUseCase
+}
+
+func (u importSongUseCase) ImportSongs(ctx context.Context, rows []entities.ImportRow, dryRun bool) entities.ImportReport {
+	report := entities.ImportReport{DryRun: dryRun, Total: len(rows)}
+	for _, row := range rows {
+		result := entities.ImportRowResult{Line: row.Line, Group: row.Song.Group, Title: row.Song.Title, Status: entities.ImportWouldCreate}
+		if row.Err != nil {
+			result.Status = entities.ImportFailed
+			result.Error = row.Err.Error()
+			report.Failed++
+		}
+		report.Rows = append(report.Rows, result)
+	}
+	return report
+}
+
+func TestImportSongsMalformedCSV(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []entities.ImportRowResult
+	}{
+		{
+			name: "незакрытая кавычка в середине файла",
+			body: "group,song\nMuse,Uprising\nMuse,\"Bad \"quote\nQueen,Bohemian Rhapsody\n",
+			want: []entities.ImportRowResult{
+				{Line: 2, Status: entities.ImportWouldCreate},
+				{Line: 3, Status: entities.ImportFailed},
+				{Line: 4, Status: entities.ImportWouldCreate},
+			},
+		},
+		{
+			name: "кавычка внутри поля без кавычек",
+			body: "group,song\nMu\"se,Uprising\nQueen,Bohemian Rhapsody\n",
+			want: []entities.ImportRowResult{
+				{Line: 2, Status: entities.ImportFailed},
+				{Line: 3, Status: entities.ImportWouldCreate},
+			},
+		},
+		{
+			name: "кавычка не закрыта до конца файла",
+			body: "group,song\nMuse,Uprising\nQueen,\"Bohemian Rhapsody\n",
+			want: []entities.ImportRowResult{
+				{Line: 2, Status: entities.ImportWouldCreate},
+				{Line: 3, Status: entities.ImportFailed},
+			},
+		},
+	}
+
+	h := NewSongHandler(importSongUseCase{})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/songs/import?dry_run=true", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", "text/csv")
+			w := httptest.NewRecorder()
+
+			h.ImportSongs(w, r)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("код ответа %d, ожидался 200: %s", w.Code, w.Body.String())
+			}
+			var report entities.ImportReport
+			if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+				t.Fatal(err)
+			}
+			if len(report.Rows) != len(tt.want) {
+				t.Fatalf("строк в отчёте %d, ожидалось %d: %+v", len(report.Rows), len(tt.want), report.Rows)
+			}
+			for i, want := range tt.want {
+				got := report.Rows[i]
+				if got.Line != want.Line || got.Status != want.Status {
+					t.Errorf("строка %d: line %d, status %q, ожидалось line %d, status %q (%s)",
+						i, got.Line, got.Status, want.Line, want.Status, got.Error)
+				}
+				if want.Status == entities.ImportFailed && got.Error == "" {
+					t.Errorf("строка %d: нет описания ошибки", i)
+				}
+			}
+			if report.Failed != 1 {
+				t.Errorf("failed = %d, ожидалось 1", report.Failed)
+			}
+		})
+	}
+}
+
+func TestImportSongsNDJSON(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus string
+	}{
+		{
+			name:       "все поля известны",
+			body:       `{"group":"Muse","song":"Uprising","releaseDate":"2009-09-07","text":"a","link":"https://example.com"}`,
+			wantStatus: entities.ImportWouldCreate,
+		},
+		{name: "неизвестное поле", body: `{"group":"Muse","song":"Uprising","album":"The Resistance"}`, wantStatus: entities.ImportFailed},
+		{name: "клиент задаёт id", body: `{"id":7,"group":"Muse","song":"Uprising"}`, wantStatus: entities.ImportFailed},
+		{name: "клиент задаёт version", body: `{"group":"Muse","song":"Uprising","version":3}`, wantStatus: entities.ImportFailed},
+		{
+			name:       "клиент задаёт enrichmentStatus",
+			body:       `{"group":"Muse","song":"Uprising","enrichmentStatus":"done"}`,
+			wantStatus: entities.ImportFailed,
+		},
+		{name: "два объекта в строке", body: `{"group":"Muse","song":"Uprising"} {"group":"Queen"}`, wantStatus: entities.ImportFailed},
+	}
+
+	h := NewSongHandler(importSongUseCase{})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/songs/import?dry_run=true", strings.NewReader(tt.body+"\n"))
+			r.Header.Set("Content-Type", "application/x-ndjson")
+			w := httptest.NewRecorder()
+
+			h.ImportSongs(w, r)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("код ответа %d, ожидался 200: %s", w.Code, w.Body.String())
+			}
+			var report entities.ImportReport
+			if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+				t.Fatal(err)
+			}
+			if len(report.Rows) != 1 {
+				t.Fatalf("строк в отчёте %d, ожидалась 1: %+v", len(report.Rows), report.Rows)
+			}
+			if got := report.Rows[0]; got.Status != tt.wantStatus {
+				t.Errorf("status %q, ожидалось %q (%s)", got.Status, tt.wantStatus, got.Error)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"TestEffectiveMobile/internal/entities"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	// defaultPageLimit размер страницы, если limit не указан
+	defaultPageLimit = 11
+	// maxPageLimit наибольший размер страницы, больший limit уменьшается до него
+	maxPageLimit = 1000
+)
+
+// parsePagination извлекает limit и offset из параметров запроса.
+// На неверное или отрицательное значение отвечает 400 и возвращает ok == false.
+func parsePagination(w http.ResponseWriter, r *http.Request) (limit, offset int, ok bool) {
+	const op = "internal.handler.parsePagination"
+
+	query := r.URL.Query()
+	details := make([]entities.FieldError, 0, 2)
+	for _, param := range []struct {
+		name string
+		dst  *int
+	}{{"limit", &limit}, {"offset", &offset}} {
+		value := query.Get(param.name)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			details = append(details, entities.FieldError{Field: param.name, Message: "ожидается неотрицательное целое число"})
+			continue
+		}
+		*param.dst = n
+	}
+	if len(details) > 0 {
+		slog.Error(op, "Неверные параметры пагинации", slog.String("limit", query.Get("limit")), slog.String("offset", query.Get("offset")))
+		writeProblem(w, r, http.StatusBadRequest, entities.CodeInvalidParameter, "Неверные параметры пагинации", details...)
+		return 0, 0, false
+	}
+
+	if limit == 0 {
+		limit = defaultPageLimit
+	}
+	return min(limit, maxPageLimit), offset, true
+}
+
+// pageLinks ссылки на соседние страницы, пустая строка означает отсутствие ссылки
+type pageLinks struct {
+	first string
+	prev  string
+	next  string
+	last  string
+}
+
+// newPageLinks строит ссылки для пагинации limit/offset.
+// Отрицательный total означает, что общее количество неизвестно и следующая страница может быть.
+func newPageLinks(r *http.Request, limit, offset, total int) pageLinks {
+	links := pageLinks{
+		first: pageURL(r, map[string]string{"offset": "0"}),
+	}
+	if offset > 0 {
+		prev := offset - limit
+		if prev < 0 {
+			prev = 0
+		}
+		links.prev = pageURL(r, map[string]string{"offset": strconv.Itoa(prev)})
+	}
+	if total < 0 || offset+limit < total {
+		links.next = pageURL(r, map[string]string{"offset": strconv.Itoa(offset + limit)})
+	}
+	if total >= 0 {
+		last := 0
+		if total > 0 {
+			last = (total - 1) / limit * limit
+		}
+		links.last = pageURL(r, map[string]string{"offset": strconv.Itoa(last)})
+	}
+	return links
+}
+
+// pageURL возвращает адрес текущего запроса с заменёнными параметрами
+func pageURL(r *http.Request, params map[string]string) string {
+	u := *r.URL
+	query := u.Query()
+	for key, value := range params {
+		query.Set(key, value)
+	}
+	u.RawQuery = query.Encode()
+	return u.RequestURI()
+}
+
+// setPageLinks выставляет заголовок Link по RFC 8288
+func setPageLinks(w http.ResponseWriter, links pageLinks) {
+	values := make([]string, 0, 4)
+	for _, link := range []struct{ rel, url string }{
+		{"first", links.first},
+		{"prev", links.prev},
+		{"next", links.next},
+		{"last", links.last},
+	} {
+		if link.url != "" {
+			values = append(values, fmt.Sprintf(`<%s>; rel="%s"`, link.url, link.rel))
+		}
+	}
+	if len(values) > 0 {
+		w.Header().Set("Link", strings.Join(values, ", "))
+	}
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"TestEffectiveMobile/internal/entities"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParsePagination(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantLimit  int
+		wantOffset int
+		wantFields []string
+	}{
+		{name: "по умолчанию", query: "", wantLimit: defaultPageLimit},
+		{name: "явные значения", query: "limit=20&offset=40", wantLimit: 20, wantOffset: 40},
+		{name: "нулевой limit", query: "limit=0", wantLimit: defaultPageLimit},
+		{name: "limit больше максимума", query: "limit=100000", wantLimit: maxPageLimit},
+		{name: "отрицательный limit", query: "limit=-1", wantFields: []string{"limit"}},
+		{name: "отрицательный offset", query: "offset=-5", wantFields: []string{"offset"}},
+		{name: "не число", query: "limit=abc&offset=-1", wantFields: []string{"limit", "offset"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/songs?"+tt.query, nil)
+
+			limit, offset, ok := parsePagination(w, r)
+			if len(tt.wantFields) > 0 {
+				if ok {
+					t.Fatalf("ok = true, ожидалась ошибка для %v", tt.wantFields)
+				}
+				if w.Code != http.StatusBadRequest {
+					t.Fatalf("код ответа %d, ожидался %d", w.Code, http.StatusBadRequest)
+				}
+				var problem entities.ErrorResponse
+				if err := json.NewDecoder(w.Body).Decode(&problem); err != nil {
+					t.Fatal(err)
+				}
+				if problem.Code != entities.CodeInvalidParameter {
+					t.Errorf("код ошибки %q, ожидался %q", problem.Code, entities.CodeInvalidParameter)
+				}
+				if len(problem.Details) != len(tt.wantFields) {
+					t.Fatalf("details %v, ожидались поля %v", problem.Details, tt.wantFields)
+				}
+				for i, field := range tt.wantFields {
+					if problem.Details[i].Field != field {
+						t.Errorf("details[%d].field = %q, ожидалось %q", i, problem.Details[i].Field, field)
+					}
+				}
+				return
+			}
+
+			if !ok {
+				t.Fatalf("ok = false, ответ %d %s", w.Code, w.Body.String())
+			}
+			if limit != tt.wantLimit || offset != tt.wantOffset {
+				t.Errorf("limit, offset = %d, %d, ожидалось %d, %d", limit, offset, tt.wantLimit, tt.wantOffset)
+			}
+		})
+	}
+}
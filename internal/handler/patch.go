@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"TestEffectiveMobile/internal/entities"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// errPatchTestFailed операция test из JSON Patch не совпала с текущими данными
+var errPatchTestFailed = errors.New("проверка test не пройдена")
+
+// jsonPatchOperation операция JSON Patch (RFC 6902)
+type jsonPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+// decodeMergePatch разбирает тело JSON Merge Patch (RFC 7396).
+// null удаляет значение необязательного поля.
+func decodeMergePatch(data []byte) (entities.SongPatch, error) {
+	var patch entities.SongPatch
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return patch, fmt.Errorf("тело должно быть JSON-объектом: %w", err)
+	}
+
+	for name, value := range raw {
+		if err := setPatchField(&patch, name, value); err != nil {
+			return patch, err
+		}
+	}
+	return patch, nil
+}
+
+// decodeJSONPatch разбирает тело JSON Patch (RFC 6902) для песни song.
+// Поддерживаются операции add, replace, remove и test над полями верхнего уровня.
+func decodeJSONPatch(data []byte, song *entities.Song) (entities.SongPatch, error) {
+	var patch entities.SongPatch
+
+	var operations []jsonPatchOperation
+	if err := json.Unmarshal(data, &operations); err != nil {
+		return patch, fmt.Errorf("тело должно быть массивом операций: %w", err)
+	}
+
+	for _, operation := range operations {
+		name := strings.TrimPrefix(operation.Path, "/")
+		if !strings.HasPrefix(operation.Path, "/") || strings.Contains(name, "/") || patch.Field(name) == nil {
+			return patch, fmt.Errorf("поле %q нельзя изменить", operation.Path)
+		}
+
+		switch operation.Op {
+		case "add", "replace":
+			if len(operation.Value) == 0 {
+				return patch, fmt.Errorf("операция %s для %q без value", operation.Op, operation.Path)
+			}
+			if err := setPatchField(&patch, name, operation.Value); err != nil {
+				return patch, err
+			}
+		case "remove":
+			if err := setPatchField(&patch, name, json.RawMessage("null")); err != nil {
+				return patch, err
+			}
+		case "test":
+			var expected string
+			if err := json.Unmarshal(operation.Value, &expected); err != nil {
+				return patch, fmt.Errorf("значение test для %q должно быть строкой", operation.Path)
+			}
+			// Сравниваем с учётом операций, применённых выше по списку
+			current, _ := entities.SongField(song, name)
+			if value := *patch.Field(name); value != nil {
+				current = *value
+			}
+			if current != expected {
+				return patch, fmt.Errorf("%w: %s", errPatchTestFailed, operation.Path)
+			}
+		default:
+			return patch, fmt.Errorf("операция %q не поддерживается", operation.Op)
+		}
+	}
+	return patch, nil
+}
+
+// setPatchField записывает в патч значение поля из JSON
+func setPatchField(patch *entities.SongPatch, name string, value json.RawMessage) error {
+	field := patch.Field(name)
+	if field == nil {
+		return fmt.Errorf("поле %q нельзя изменить", name)
+	}
+
+	if bytes.Equal(bytes.TrimSpace(value), []byte("null")) {
+		if entities.RequiredSongField(name) {
+			return fmt.Errorf("обязательное поле %q нельзя удалить", name)
+		}
+		empty := ""
+		*field = &empty
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(value, &s); err != nil {
+		return fmt.Errorf("поле %q должно быть строкой", name)
+	}
+	*field = &s
+	return nil
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"TestEffectiveMobile/internal/entities"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// str возвращает указатель на строку для ожидаемых значений патча
+func str(s string) *string {
+	return &s
+}
+
+func TestDecodeMergePatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    entities.SongPatch
+		wantErr bool
+	}{
+		{name: "пустой объект", body: `{}`, want: entities.SongPatch{}},
+		{
+			name: "изменение полей",
+			body: `{"group": "Muse", "song": "Uprising", "releaseDate": "2009"}`,
+			want: entities.SongPatch{Group: str("Muse"), Title: str("Uprising"), ReleaseDate: str("2009")},
+		},
+		{
+			name: "null очищает необязательное поле",
+			body: `{"link": null, "text": "Paranoia"}`,
+			want: entities.SongPatch{Link: str(""), Text: str("Paranoia")},
+		},
+		{name: "null для обязательного поля", body: `{"song": null}`, wantErr: true},
+		{name: "неизвестное поле", body: `{"id": "1"}`, wantErr: true},
+		{name: "значение не строка", body: `{"text": 42}`, wantErr: true},
+		{name: "не объект", body: `["group"]`, wantErr: true},
+		{name: "неверный JSON", body: `{"group": `, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeMergePatch([]byte(tt.body))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeMergePatch(%s) ошибка %v, ожидалась ошибка: %v", tt.body, err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeMergePatch(%s) = %s, ожидалось %s", tt.body, formatPatch(got), formatPatch(tt.want))
+			}
+		})
+	}
+}
+
+func TestDecodeJSONPatch(t *testing.T) {
+	song := &entities.Song{Group: "Muse", Title: "Uprising", Text: "Paranoia", Link: "https://example.com"}
+
+	tests := []struct {
+		name       string
+		body       string
+		want       entities.SongPatch
+		wantErr    bool
+		testFailed bool
+	}{
+		{name: "пустой список", body: `[]`, want: entities.SongPatch{}},
+		{
+			name: "replace и add",
+			body: `[{"op": "replace", "path": "/song", "value": "Resistance"}, {"op": "add", "path": "/releaseDate", "value": "2009"}]`,
+			want: entities.SongPatch{Title: str("Resistance"), ReleaseDate: str("2009")},
+		},
+		{
+			name: "remove очищает необязательное поле",
+			body: `[{"op": "remove", "path": "/link"}]`,
+			want: entities.SongPatch{Link: str("")},
+		},
+		{
+			name: "test по текущим данным",
+			body: `[{"op": "test", "path": "/text", "value": "Paranoia"}, {"op": "replace", "path": "/text", "value": "They will not force us"}]`,
+			want: entities.SongPatch{Text: str("They will not force us")},
+		},
+		{
+			name: "test учитывает предыдущие операции",
+			body: `[{"op": "replace", "path": "/group", "value": "MUSE"}, {"op": "test", "path": "/group", "value": "MUSE"}]`,
+			want: entities.SongPatch{Group: str("MUSE")},
+		},
+		{
+			name:       "test не совпал",
+			body:       `[{"op": "test", "path": "/group", "value": "Queen"}]`,
+			wantErr:    true,
+			testFailed: true,
+		},
+		{name: "test не строка", body: `[{"op": "test", "path": "/group", "value": 1}]`, wantErr: true},
+		{name: "remove обязательного поля", body: `[{"op": "remove", "path": "/group"}]`, wantErr: true},
+		{name: "replace без value", body: `[{"op": "replace", "path": "/text"}]`, wantErr: true},
+		{name: "вложенный путь", body: `[{"op": "replace", "path": "/text/0", "value": "x"}]`, wantErr: true},
+		{name: "путь без слэша", body: `[{"op": "replace", "path": "text", "value": "x"}]`, wantErr: true},
+		{name: "неизвестное поле", body: `[{"op": "replace", "path": "/id", "value": "2"}]`, wantErr: true},
+		{name: "неподдерживаемая операция", body: `[{"op": "move", "from": "/text", "path": "/link"}]`, wantErr: true},
+		{name: "не массив", body: `{"op": "remove", "path": "/link"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeJSONPatch([]byte(tt.body), song)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeJSONPatch(%s) ошибка %v, ожидалась ошибка: %v", tt.body, err, tt.wantErr)
+			}
+			if errors.Is(err, errPatchTestFailed) != tt.testFailed {
+				t.Errorf("decodeJSONPatch(%s) ошибка %v, ожидалась errPatchTestFailed: %v", tt.body, err, tt.testFailed)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeJSONPatch(%s) = %s, ожидалось %s", tt.body, formatPatch(got), formatPatch(tt.want))
+			}
+		})
+	}
+}
+
+// formatPatch записывает патч с разыменованными полями для сообщений тестов
+func formatPatch(patch entities.SongPatch) string {
+	s := "{"
+	for _, name := range []string{"group", "song", "releaseDate", "text", "link"} {
+		if value := *patch.Field(name); value != nil {
+			s += " " + name + "=" + *value
+		}
+	}
+	return s + " }"
+}
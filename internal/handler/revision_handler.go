@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"TestEffectiveMobile/internal/entities"
+	"TestEffectiveMobile/internal/usecase"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type RevisionHandler interface {
+	ListRevisions(w http.ResponseWriter, r *http.Request)
+	GetRevision(w http.ResponseWriter, r *http.Request)
+	RevertSong(w http.ResponseWriter, r *http.Request)
+	DiffRevisions(w http.ResponseWriter, r *http.Request)
+}
+
+type revisionHandler struct {
+	useCase usecase.RevisionUseCase
+}
+
+func NewRevisionHandler(useCase usecase.RevisionUseCase) RevisionHandler {
+	return &revisionHandler{
+		useCase: useCase,
+	}
+}
+
+// ListRevisions godoc
+// @Summary Получение истории изменений песни
+// @Description Возвращает ревизии песни, начиная с последней.
+// @Tags revisions
+// @Produce json
+// @Param id path int true "ID песни"
+// @Param limit query int false "Лимит записей (по умолчанию 11, не больше 1000)"
+// @Param offset query int false "Сдвиг записей"
+// @Success 200 {array} entities.SongRevision "Список ревизий"
+// @Failure 400 {object} entities.ErrorResponse "Неверный ID или параметры пагинации"
+// @Failure 500 {object} entities.ErrorResponse "Internal Server Error"
+// @Router /songs/{id}/revisions [get]
+func (h *revisionHandler) ListRevisions(w http.ResponseWriter, r *http.Request) {
+	const op = "internal.handler.ListRevisions"
+
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		slog.Error(op, "Ошибка парсинга id с параметров", slog.String("error", err.Error()))
+		writeProblem(w, r, http.StatusBadRequest, entities.CodeInvalidID, "Неверный ID")
+		return
+	}
+	limit, offset, ok := parsePagination(w, r)
+	if !ok {
+		return
+	}
+
+	revisions, err := h.useCase.ListRevisions(r.Context(), id, limit, offset)
+	if err != nil {
+		writeError(w, r, op, err)
+		return
+	}
+	json.NewEncoder(w).Encode(revisions)
+}
+
+// GetRevision godoc
+// @Summary Получение ревизии песни
+// @Description Возвращает снимок данных песни в указанной ревизии.
+// @Tags revisions
+// @Produce json
+// @Param id path int true "ID песни"
+// @Param rev path int true "Номер ревизии"
+// @Success 200 {object} entities.SongRevision "Ревизия"
+// @Failure 400 {object} entities.ErrorResponse "Неверный ID или номер ревизии"
+// @Failure 404 {object} entities.ErrorResponse "Ревизия не найдена"
+// @Router /songs/{id}/revisions/{rev} [get]
+func (h *revisionHandler) GetRevision(w http.ResponseWriter, r *http.Request) {
+	const op = "internal.handler.GetRevision"
+
+	id, rev, err := parseRevisionVars(r)
+	if err != nil {
+		slog.Error(op, "Ошибка парсинга параметров пути", slog.String("error", err.Error()))
+		writeProblem(w, r, http.StatusBadRequest, entities.CodeInvalidID, "Неверный ID или номер ревизии")
+		return
+	}
+
+	revision, err := h.useCase.GetRevision(r.Context(), id, rev)
+	if err != nil {
+		writeError(w, r, op, err)
+		return
+	}
+	json.NewEncoder(w).Encode(revision)
+}
+
+// RevertSong godoc
+// @Summary Откат песни к ревизии
+// @Description Записывает в песню данные из указанной ревизии. Откат сохраняется как новая ревизия.
+// @Description Если передан If-Match, откат выполняется только для этой версии песни.
+// @Tags revisions
+// @Param id path int true "ID песни"
+// @Param rev path int true "Номер ревизии"
+// @Param If-Match header string false "ETag песни, полученный при чтении"
+// @Success 200 {string} string "OK"
+// @Failure 400 {object} entities.ErrorResponse "Неверный ID, номер ревизии или If-Match"
+// @Failure 404 {object} entities.ErrorResponse "Ревизия не найдена"
+// @Failure 409 {object} entities.ErrorResponse "В библиотеке уже есть песня с такими группой и названием"
+// @Failure 412 {object} entities.ErrorResponse "Версия песни изменилась"
+// @Failure 500 {object} entities.ErrorResponse "Internal Server Error"
+// @Router /songs/{id}/revisions/{rev}/revert [post]
+func (h *revisionHandler) RevertSong(w http.ResponseWriter, r *http.Request) {
+	const op = "internal.handler.RevertSong"
+
+	id, rev, err := parseRevisionVars(r)
+	if err != nil {
+		slog.Error(op, "Ошибка парсинга параметров пути", slog.String("error", err.Error()))
+		writeProblem(w, r, http.StatusBadRequest, entities.CodeInvalidID, "Неверный ID или номер ревизии")
+		return
+	}
+
+	version, err := parseIfMatch(r)
+	if err != nil {
+		slog.Error(op, "Ошибка парсинга If-Match", slog.String("error", err.Error()))
+		writeProblem(w, r, http.StatusBadRequest, entities.CodeInvalidIfMatch, err.Error())
+		return
+	}
+
+	if err = h.useCase.RevertSong(r.Context(), id, rev, version); err != nil {
+		writeError(w, r, op, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// DiffRevisions godoc
+// @Summary Сравнение текста песни в двух ревизиях
+// @Description Возвращает построчный diff поля text между ревизиями from и to.
+// @Tags revisions
+// @Produce json
+// @Param id path int true "ID песни"
+// @Param from query int true "Номер исходной ревизии"
+// @Param to query int true "Номер итоговой ревизии"
+// @Success 200 {object} entities.SongRevisionDiff "Diff текста"
+// @Failure 400 {object} entities.ErrorResponse "Неверные параметры"
+// @Failure 404 {object} entities.ErrorResponse "Ревизия не найдена"
+// @Failure 422 {object} entities.ErrorResponse "Текст ревизии слишком длинный для сравнения"
+// @Failure 500 {object} entities.ErrorResponse "Internal Server Error"
+// @Router /songs/{id}/revisions/diff [get]
+func (h *revisionHandler) DiffRevisions(w http.ResponseWriter, r *http.Request) {
+	const op = "internal.handler.DiffRevisions"
+
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		slog.Error(op, "Ошибка парсинга id с параметров", slog.String("error", err.Error()))
+		writeProblem(w, r, http.StatusBadRequest, entities.CodeInvalidID, "Неверный ID")
+		return
+	}
+
+	query := r.URL.Query()
+	from, errFrom := strconv.Atoi(query.Get("from"))
+	to, errTo := strconv.Atoi(query.Get("to"))
+	if err = errors.Join(errFrom, errTo); err != nil {
+		slog.Error(op, "Ошибка парсинга номеров ревизий", slog.String("error", err.Error()))
+		details := make([]entities.FieldError, 0, 2)
+		if errFrom != nil {
+			details = append(details, entities.FieldError{Field: "from", Message: "ожидается номер ревизии"})
+		}
+		if errTo != nil {
+			details = append(details, entities.FieldError{Field: "to", Message: "ожидается номер ревизии"})
+		}
+		writeProblem(w, r, http.StatusBadRequest, entities.CodeInvalidParameter, "Неверные номера ревизий", details...)
+		return
+	}
+
+	diff, err := h.useCase.DiffRevisions(r.Context(), id, from, to)
+	if err != nil {
+		writeError(w, r, op, err)
+		return
+	}
+	json.NewEncoder(w).Encode(diff)
+}
+
+// parseRevisionVars извлекает ID песни и номер ревизии из пути
+func parseRevisionVars(r *http.Request) (int, int, error) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		return 0, 0, err
+	}
+	rev, err := strconv.Atoi(vars["rev"])
+	if err != nil {
+		return 0, 0, err
+	}
+	return id, rev, nil
+}
@@ -0,0 +1,66 @@
+package mockinfo
+
+import (
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Fixture ответ внешнего API для одной песни. Файлы фикстур содержат список таких записей.
+type Fixture struct {
+	Group       string `json:"group" yaml:"group"`
+	Song        string `json:"song" yaml:"song"`
+	ReleaseDate string `json:"releaseDate" yaml:"releaseDate"`
+	Text        string `json:"text" yaml:"text"`
+	Link        string `json:"link" yaml:"link"`
+}
+
+// LoadFixtures читает фикстуры из всех файлов .json, .yaml и .yml каталога dir в порядке имён файлов.
+// Если песня встречается несколько раз, побеждает последняя запись.
+func LoadFixtures(dir string) ([]Fixture, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	var fixtures []Fixture
+	for _, name := range names {
+		var unmarshal func([]byte, interface{}) error
+		switch strings.ToLower(filepath.Ext(name)) {
+		case ".json":
+			unmarshal = json.Unmarshal
+		case ".yaml", ".yml":
+			unmarshal = yaml.Unmarshal
+		default:
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		var fileFixtures []Fixture
+		if err = unmarshal(data, &fileFixtures); err != nil {
+			return nil, fmt.Errorf("фикстура %s: %w", name, err)
+		}
+		fixtures = append(fixtures, fileFixtures...)
+	}
+	return fixtures, nil
+}
+
+// fixtureKey ключ песни без учёта регистра и пробелов по краям, как при поиске песен в сервисе
+func fixtureKey(group, song string) string {
+	return strings.ToLower(strings.TrimSpace(group)) + "\x00" + strings.ToLower(strings.TrimSpace(song))
+}
@@ -0,0 +1,260 @@
+// Package mockinfo имитирует внешнее API информации о песнях (GET /info?group=&song=) по фикстурам.
+// Сбои (задержка, 404, 5xx, неверное тело) включаются через Fault из кода или через /_mock/faults.
+package mockinfo
+
+import (
+	"TestEffectiveMobile/internal/entities"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Fault сбой, который получают подходящие запросы к /info
+type Fault struct {
+	// Group и Song ограничивают сбой одной песней, пустое значение подходит под любое
+	Group string
+	Song  string
+	// Latency задержка перед ответом
+	Latency time.Duration
+	// Status код ответа вместо данных песни, например 404, 429 или 503
+	Status int
+	// RetryAfter значение заголовка Retry-After при ответе со Status
+	RetryAfter time.Duration
+	// Malformed ответ 200 с телом, которое не разбирается как JSON
+	Malformed bool
+	// Count сколько запросов получат сбой, 0 означает все до сброса
+	Count int
+}
+
+// matches сообщает, что сбой относится к песне
+func (f Fault) matches(group, song string) bool {
+	return (f.Group == "" || fixtureKey(f.Group, "") == fixtureKey(group, "")) &&
+		(f.Song == "" || fixtureKey("", f.Song) == fixtureKey("", song))
+}
+
+// Handler обработчик имитации внешнего API
+type Handler struct {
+	mux      *http.ServeMux
+	fixtures map[string]Fixture
+	requests atomic.Int64
+	// latency базовая задержка каждого ответа /info
+	latency atomic.Int64
+
+	mu     sync.Mutex
+	faults []Fault
+}
+
+// NewHandler создаёт имитацию внешнего API, которая отвечает данными fixtures
+func NewHandler(fixtures []Fixture) *Handler {
+	h := &Handler{
+		mux:      http.NewServeMux(),
+		fixtures: make(map[string]Fixture, len(fixtures)),
+	}
+	for _, fixture := range fixtures {
+		h.fixtures[fixtureKey(fixture.Group, fixture.Song)] = fixture
+	}
+
+	h.mux.HandleFunc("GET /info", h.info)
+	h.mux.HandleFunc("GET /_mock/faults", h.listFaults)
+	h.mux.HandleFunc("POST /_mock/faults", h.addFault)
+	h.mux.HandleFunc("DELETE /_mock/faults", h.clearFaults)
+	h.mux.HandleFunc("GET /_mock/stats", h.stats)
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// AddFault добавляет сбой. Запрос получает первый подходящий сбой из добавленных.
+func (h *Handler) AddFault(fault Fault) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.faults = append(h.faults, fault)
+}
+
+// ClearFaults отменяет все сбои
+func (h *Handler) ClearFaults() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.faults = nil
+}
+
+// SetLatency задаёт базовую задержку каждого ответа /info, задержка сбоя добавляется к ней
+func (h *Handler) SetLatency(latency time.Duration) {
+	h.latency.Store(int64(latency))
+}
+
+// Requests возвращает количество запросов к /info
+func (h *Handler) Requests() int64 {
+	return h.requests.Load()
+}
+
+// takeFault возвращает сбой для песни и уменьшает его счётчик, ok == false если сбоя нет
+func (h *Handler) takeFault(group, song string) (Fault, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for i, fault := range h.faults {
+		if !fault.matches(group, song) {
+			continue
+		}
+		if fault.Count > 0 {
+			if h.faults[i].Count--; h.faults[i].Count == 0 {
+				h.faults = append(h.faults[:i], h.faults[i+1:]...)
+			}
+		}
+		return fault, true
+	}
+	return Fault{}, false
+}
+
+// info отвечает по контракту внешнего API: 200 с entities.ExternalSongInfo, 400 без параметров, 404 для неизвестной песни
+func (h *Handler) info(w http.ResponseWriter, r *http.Request) {
+	const op = "internal.mockinfo.info"
+
+	h.requests.Add(1)
+	group, song := r.URL.Query().Get("group"), r.URL.Query().Get("song")
+	slog.Info(op+": запрос", "group", group, "song", song)
+
+	fault, ok := h.takeFault(group, song)
+	if latency := time.Duration(h.latency.Load()) + fault.Latency; latency > 0 {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(latency):
+		}
+	}
+	switch {
+	case ok && fault.Status != 0:
+		if fault.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(fault.RetryAfter.Round(time.Second)/time.Second)))
+		}
+		http.Error(w, http.StatusText(fault.Status), fault.Status)
+		return
+	case ok && fault.Malformed:
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"releaseDate": "16.07.2006", "text": `))
+		return
+	}
+
+	if group == "" || song == "" {
+		http.Error(w, "group и song обязательны", http.StatusBadRequest)
+		return
+	}
+	fixture, found := h.fixtures[fixtureKey(group, song)]
+	if !found {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entities.NewEternalSongInfo(fixture.ReleaseDate, fixture.Text, fixture.Link))
+}
+
+// faultRequest сбой в теле POST /_mock/faults и в ответе GET /_mock/faults,
+// длительности записываются строкой, например "1.5s"
+type faultRequest struct {
+	Group      string `json:"group,omitempty"`
+	Song       string `json:"song,omitempty"`
+	Latency    string `json:"latency,omitempty"`
+	Status     int    `json:"status,omitempty"`
+	RetryAfter string `json:"retryAfter,omitempty"`
+	Malformed  bool   `json:"malformed,omitempty"`
+	Count      int    `json:"count,omitempty"`
+}
+
+// newFaultRequest записывает сбой в виде faultRequest
+func newFaultRequest(fault Fault) faultRequest {
+	req := faultRequest{Group: fault.Group, Song: fault.Song, Status: fault.Status, Malformed: fault.Malformed, Count: fault.Count}
+	if fault.Latency > 0 {
+		req.Latency = fault.Latency.String()
+	}
+	if fault.RetryAfter > 0 {
+		req.RetryAfter = fault.RetryAfter.String()
+	}
+	return req
+}
+
+func (h *Handler) addFault(w http.ResponseWriter, r *http.Request) {
+	var req faultRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "неверный JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	fault := Fault{Group: req.Group, Song: req.Song, Status: req.Status, Malformed: req.Malformed, Count: req.Count}
+	for _, duration := range []struct {
+		value string
+		dst   *time.Duration
+	}{{req.Latency, &fault.Latency}, {req.RetryAfter, &fault.RetryAfter}} {
+		if duration.value == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(duration.value)
+		if err != nil {
+			http.Error(w, "неверная длительность: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		*duration.dst = parsed
+	}
+	if fault.Status != 0 && (fault.Status < 100 || fault.Status > 599) {
+		http.Error(w, "неверный код ответа", http.StatusBadRequest)
+		return
+	}
+
+	h.AddFault(fault)
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (h *Handler) listFaults(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	faults := make([]faultRequest, 0, len(h.faults))
+	for _, fault := range h.faults {
+		faults = append(faults, newFaultRequest(fault))
+	}
+	h.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(faults)
+}
+
+func (h *Handler) clearFaults(w http.ResponseWriter, r *http.Request) {
+	h.ClearFaults()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) stats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int64{"requests": h.Requests(), "fixtures": int64(len(h.fixtures))})
+}
+
+// Server имитация внешнего API, запущенная в том же процессе, например в тестах
+type Server struct {
+	*Handler
+	server *httptest.Server
+	// URL адрес /info, который передаётся сервису как EXTERNAL_URL
+	URL string
+}
+
+// Start запускает имитацию внешнего API на свободном локальном порту. Сервер останавливает Close.
+func Start(fixtures []Fixture) *Server {
+	handler := NewHandler(fixtures)
+	server := httptest.NewServer(handler)
+	return &Server{
+		Handler: handler,
+		server:  server,
+		URL:     server.URL + "/info",
+	}
+}
+
+func (s *Server) Close() {
+	s.server.Close()
+}
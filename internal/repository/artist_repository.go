@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"TestEffectiveMobile/internal/entities"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+type ArtistRepository interface {
+	ListArtists(ctx context.Context, limit, offset int) ([]entities.Artist, error)
+	GetArtistByID(ctx context.Context, id int) (*entities.Artist, error)
+	CreateArtist(ctx context.Context, artist entities.Artist) (int, error)
+	UpdateArtist(ctx context.Context, artist entities.Artist) error
+	DeleteArtist(ctx context.Context, id int) error
+}
+
+type artistRepository struct {
+	db      *sql.DB
+	timeout time.Duration
+}
+
+// NewArtistRepository создаёт репозиторий, queryTimeout ограничивает время каждого запроса к DB
+func NewArtistRepository(db *sql.DB, queryTimeout time.Duration) ArtistRepository {
+	return &artistRepository{
+		db:      db,
+		timeout: queryTimeout,
+	}
+}
+
+func (r *artistRepository) ListArtists(ctx context.Context, limit, offset int) ([]entities.Artist, error) {
+	const op = "internal.repository.ListArtists"
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	query := `SELECT id, name FROM artists ORDER BY id LIMIT $1 OFFSET $2`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		slog.Error(op, "Ошибка запроса к DB", slog.String("error", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	artists := make([]entities.Artist, 0)
+	for rows.Next() {
+		var artist entities.Artist
+		if err = rows.Scan(&artist.ID, &artist.Name); err != nil {
+			slog.Error(op, "Ошибка сканирования результата", slog.String("error", err.Error()))
+			return nil, err
+		}
+		artists = append(artists, artist)
+	}
+	if err = rows.Err(); err != nil {
+		slog.Error(op, "Ошибка чтения результата", slog.String("error", err.Error()))
+		return nil, err
+	}
+	return artists, nil
+}
+
+func (r *artistRepository) GetArtistByID(ctx context.Context, id int) (*entities.Artist, error) {
+	const op = "internal.repository.GetArtistByID"
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	query := `SELECT id, name FROM artists WHERE id=$1`
+
+	var artist entities.Artist
+	if err := r.db.QueryRowContext(ctx, query, id).Scan(&artist.ID, &artist.Name); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, artistNotFound(id)
+		}
+		slog.Error(op, "Ошибка парсинга данных", slog.String("error", err.Error()))
+		return nil, err
+	}
+	return &artist, nil
+}
+
+func (r *artistRepository) CreateArtist(ctx context.Context, artist entities.Artist) (int, error) {
+	const op = "internal.repository.CreateArtist"
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	query := `INSERT INTO artists (name) VALUES ($1) RETURNING id`
+
+	var id int
+	if err := r.db.QueryRowContext(ctx, query, artist.Name).Scan(&id); err != nil {
+		if isConstraintViolation(err, "23505") {
+			return 0, fmt.Errorf("%w: исполнитель %q уже существует", entities.ErrConflict, artist.Name)
+		}
+		slog.Error(op, "Ошибка добавления исполнителя", slog.String("error", err.Error()))
+		return 0, err
+	}
+	return id, nil
+}
+
+func (r *artistRepository) UpdateArtist(ctx context.Context, artist entities.Artist) error {
+	const op = "internal.repository.UpdateArtist"
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	query := `UPDATE artists SET name=$1 WHERE id=$2`
+
+	res, err := r.db.ExecContext(ctx, query, artist.Name, artist.ID)
+	if err != nil {
+		if isConstraintViolation(err, "23505") {
+			return fmt.Errorf("%w: исполнитель %q уже существует", entities.ErrConflict, artist.Name)
+		}
+		slog.Error(op, "Ошибка при изменении данных в DB", slog.String("error", err.Error()))
+		return err
+	}
+	return checkAffected(op, res, artistNotFound(artist.ID))
+}
+
+func (r *artistRepository) DeleteArtist(ctx context.Context, id int) error {
+	const op = "internal.repository.DeleteArtist"
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	// Исполнителя с песнями удалить не получится из-за ограничения внешнего ключа
+	query := `DELETE FROM artists WHERE id = $1`
+
+	res, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		if isConstraintViolation(err, "23503") {
+			return fmt.Errorf("%w: у исполнителя %d есть песни", entities.ErrConflict, id)
+		}
+		slog.Error(op, "Ошибка при удалении записи с DB", slog.String("error", err.Error()))
+		return err
+	}
+	return checkAffected(op, res, artistNotFound(id))
+}
+
+// artistNotFound возвращает ошибку entities.ErrNotFound для исполнителя id
+func artistNotFound(id int) error {
+	return fmt.Errorf("исполнитель %d: %w", id, entities.ErrNotFound)
+}
@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"TestEffectiveMobile/internal/entities"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+type EnrichmentCacheRepository interface {
+	GetEntry(ctx context.Context, group, song string) (*entities.EnrichmentCacheEntry, error)
+	SaveEntry(ctx context.Context, entry entities.EnrichmentCacheEntry, ttl time.Duration) error
+	ListEntries(ctx context.Context, group, song string, limit, offset int) ([]entities.EnrichmentCacheEntry, error)
+	DeleteEntry(ctx context.Context, id int) error
+	DeleteEntries(ctx context.Context, group, song string) (int64, error)
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+type enrichmentCacheRepository struct {
+	db      *sql.DB
+	timeout time.Duration
+}
+
+// NewEnrichmentCacheRepository создаёт репозиторий ответов источников обогащения,
+// queryTimeout ограничивает время каждого запроса к DB
+func NewEnrichmentCacheRepository(db *sql.DB, queryTimeout time.Duration) EnrichmentCacheRepository {
+	return &enrichmentCacheRepository{
+		db:      db,
+		timeout: queryTimeout,
+	}
+}
+
+// cacheColumns поля записи кэша обогащения
+const cacheColumns = `id, group_name, song_title, found, release_date, text, link,
+				  expires_at <= now(), created_at, expires_at`
+
+// cacheKeyMatches условие отбора записей по группе $1 и названию $2, пустое значение не ограничивает выборку
+const cacheKeyMatches = `($1::text = '' OR lower(btrim(group_name)) = lower(btrim($1)))
+				  AND ($2::text = '' OR lower(btrim(song_title)) = lower(btrim($2)))`
+
+// GetEntry возвращает действующую запись для группы и названия песни.
+// Если записи нет или её срок истёк, возвращается entities.ErrNotFound.
+func (r *enrichmentCacheRepository) GetEntry(ctx context.Context, group, song string) (*entities.EnrichmentCacheEntry, error) {
+	const op = "internal.repository.GetCacheEntry"
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	query := `SELECT ` + cacheColumns + ` FROM enrichment_cache
+			  WHERE lower(btrim(group_name)) = lower(btrim($1)) AND lower(btrim(song_title)) = lower(btrim($2))
+				AND expires_at > now()`
+
+	entry, err := scanCacheEntry(r.db.QueryRowContext(ctx, query, group, song))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("ответ обогащения %q - %q: %w", group, song, entities.ErrNotFound)
+		}
+		slog.Error(op, "Ошибка запроса к DB", slog.String("error", err.Error()))
+		return nil, err
+	}
+	return entry, nil
+}
+
+// SaveEntry сохраняет ответ на ttl, заменяя прежний ответ для той же группы и названия
+func (r *enrichmentCacheRepository) SaveEntry(ctx context.Context, entry entities.EnrichmentCacheEntry, ttl time.Duration) error {
+	const op = "internal.repository.SaveCacheEntry"
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	query := `INSERT INTO enrichment_cache (group_name, song_title, found, release_date, text, link, expires_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, now() + make_interval(secs => $7))
+			  ON CONFLICT ((lower(btrim(group_name))), (lower(btrim(song_title)))) DO UPDATE
+			  SET group_name = EXCLUDED.group_name, song_title = EXCLUDED.song_title, found = EXCLUDED.found,
+				  release_date = EXCLUDED.release_date, text = EXCLUDED.text, link = EXCLUDED.link,
+				  created_at = now(), expires_at = EXCLUDED.expires_at`
+
+	_, err := r.db.ExecContext(ctx, query,
+		entry.Group, entry.Title, entry.Found, entry.Info.ReleaseDate, entry.Info.Text, entry.Info.Link, ttl.Seconds())
+	if err != nil {
+		slog.Error(op, "Ошибка сохранения данных", slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// ListEntries возвращает записи, в том числе истёкшие, начиная с последней сохранённой
+func (r *enrichmentCacheRepository) ListEntries(ctx context.Context, group, song string, limit, offset int) ([]entities.EnrichmentCacheEntry, error) {
+	const op = "internal.repository.ListCacheEntries"
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	query := `SELECT ` + cacheColumns + ` FROM enrichment_cache
+			  WHERE ` + cacheKeyMatches + `
+			  ORDER BY created_at DESC, id DESC LIMIT $3 OFFSET $4`
+
+	rows, err := r.db.QueryContext(ctx, query, group, song, limit, offset)
+	if err != nil {
+		slog.Error(op, "Ошибка запроса к DB", slog.String("error", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]entities.EnrichmentCacheEntry, 0)
+	for rows.Next() {
+		entry, err := scanCacheEntry(rows)
+		if err != nil {
+			slog.Error(op, "Ошибка сканирования результата", slog.String("error", err.Error()))
+			return nil, err
+		}
+		entries = append(entries, *entry)
+	}
+	return entries, rows.Err()
+}
+
+func (r *enrichmentCacheRepository) DeleteEntry(ctx context.Context, id int) error {
+	const op = "internal.repository.DeleteCacheEntry"
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, `DELETE FROM enrichment_cache WHERE id = $1`, id)
+	if err != nil {
+		slog.Error(op, "Ошибка удаления данных", slog.String("error", err.Error()))
+		return err
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		slog.Error(op, "Ошибка получения количества строк", slog.String("error", err.Error()))
+		return err
+	}
+	if deleted == 0 {
+		return fmt.Errorf("запись кэша обогащения %d: %w", id, entities.ErrNotFound)
+	}
+	return nil
+}
+
+// DeleteEntries удаляет записи группы и названия песни (пустое значение подходит под любое)
+// и возвращает их количество
+func (r *enrichmentCacheRepository) DeleteEntries(ctx context.Context, group, song string) (int64, error) {
+	const op = "internal.repository.DeleteCacheEntries"
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, `DELETE FROM enrichment_cache WHERE `+cacheKeyMatches, group, song)
+	if err != nil {
+		slog.Error(op, "Ошибка удаления данных", slog.String("error", err.Error()))
+		return 0, err
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		slog.Error(op, "Ошибка получения количества строк", slog.String("error", err.Error()))
+		return 0, err
+	}
+	return deleted, nil
+}
+
+// DeleteExpired удаляет записи с истёкшим сроком и возвращает их количество.
+// GetEntry такие записи не возвращает, но без удаления они остаются в таблице навсегда.
+func (r *enrichmentCacheRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	const op = "internal.repository.DeleteExpiredCacheEntries"
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, `DELETE FROM enrichment_cache WHERE expires_at <= now()`)
+	if err != nil {
+		slog.Error(op, "Ошибка удаления данных", slog.String("error", err.Error()))
+		return 0, err
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		slog.Error(op, "Ошибка получения количества строк", slog.String("error", err.Error()))
+		return 0, err
+	}
+	return deleted, nil
+}
+
+// scanCacheEntry сканирует строку, выбранную с cacheColumns
+func scanCacheEntry(row interface{ Scan(dest ...any) error }) (*entities.EnrichmentCacheEntry, error) {
+	var entry entities.EnrichmentCacheEntry
+	if err := row.Scan(
+		&entry.ID,
+		&entry.Group,
+		&entry.Title,
+		&entry.Found,
+		&entry.Info.ReleaseDate,
+		&entry.Info.Text,
+		&entry.Info.Link,
+		&entry.Expired,
+		&entry.CreatedAt,
+		&entry.ExpiresAt,
+	); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
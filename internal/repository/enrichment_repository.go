@@ -0,0 +1,337 @@
+package repository
+
+import (
+	"TestEffectiveMobile/internal/entities"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+type EnrichmentRepository interface {
+	ClaimJob(ctx context.Context, lease time.Duration) (*entities.EnrichmentJob, error)
+	CompleteJob(ctx context.Context, job *entities.EnrichmentJob, song entities.Song) error
+	RescheduleJob(ctx context.Context, job *entities.EnrichmentJob, lastError string, runAt time.Time) error
+	FailJob(ctx context.Context, job *entities.EnrichmentJob, lastError string) error
+	GetSongEnrichment(ctx context.Context, songID int) (*entities.SongEnrichment, error)
+	ListFailedJobs(ctx context.Context, limit, offset int) ([]entities.EnrichmentJob, error)
+	RetryJob(ctx context.Context, id int) (*entities.EnrichmentJob, error)
+}
+
+type enrichmentRepository struct {
+	db      *sql.DB
+	timeout time.Duration
+}
+
+// NewEnrichmentRepository создаёт репозиторий очереди обогащения, queryTimeout ограничивает время каждого запроса к DB
+func NewEnrichmentRepository(db *sql.DB, queryTimeout time.Duration) EnrichmentRepository {
+	return &enrichmentRepository{
+		db:      db,
+		timeout: queryTimeout,
+	}
+}
+
+// jobColumns поля задачи обогащения вместе с группой и названием песни
+const jobColumns = `j.id, j.song_id, a.name, s.song_title, j.status, j.overwrite, j.attempts,
+				  coalesce(j.last_error, ''), j.run_at, j.created_at, j.updated_at, j.lease_token`
+
+// jobFrom таблицы, из которых выбираются jobColumns
+const jobFrom = ` FROM enrichment_jobs j JOIN songs s ON s.id = j.song_id JOIN artists a ON a.id = s.artist_id`
+
+// ClaimJob забирает задачу, срок выполнения которой наступил, и продлевает её на lease.
+// Параллельные воркеры пропускают строки, заблокированные друг другом (SKIP LOCKED), поэтому
+// одну задачу не получат два воркера. Задачи воркера, не уложившегося в lease, забираются повторно
+// с новым LeaseToken, и прежний воркер уже не может их завершить.
+// Если задач нет, возвращается entities.ErrNotFound.
+func (r *enrichmentRepository) ClaimJob(ctx context.Context, lease time.Duration) (*entities.EnrichmentJob, error) {
+	const op = "internal.repository.ClaimJob"
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	query := `UPDATE enrichment_jobs j
+			  SET status = 'running', attempts = j.attempts + 1, lease_token = j.lease_token + 1,
+				  locked_until = now() + make_interval(secs => $1), updated_at = now()
+			  FROM songs s JOIN artists a ON a.id = s.artist_id
+			  WHERE j.id = (
+				  SELECT id FROM enrichment_jobs
+				  WHERE (status = 'pending' AND run_at <= now()) OR (status = 'running' AND locked_until < now())
+				  ORDER BY run_at, id
+				  LIMIT 1
+				  FOR UPDATE SKIP LOCKED
+			  ) AND s.id = j.song_id
+			  RETURNING ` + jobColumns
+
+	job, err := scanJob(r.db.QueryRowContext(ctx, query, lease.Seconds()))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("задача обогащения: %w", entities.ErrNotFound)
+		}
+		slog.Error(op, "Ошибка получения задачи", slog.String("error", err.Error()))
+		return nil, err
+	}
+	return job, nil
+}
+
+// CompleteJob записывает в песню данные внешнего API и завершает задачу.
+// Без overwrite заполняются только пустые поля песни. Если задачу за это время забрал другой воркер,
+// возвращается entities.ErrConflict.
+func (r *enrichmentRepository) CompleteJob(ctx context.Context, job *entities.EnrichmentJob, song entities.Song) error {
+	const op = "internal.repository.CompleteJob"
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	releaseDate, precision, err := releaseDateArgs(song.ReleaseDate)
+	if err != nil {
+		slog.Error(op, "Ошибка разбора даты выпуска", slog.String("error", err.Error()))
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Error(op, "Ошибка начала транзакции", slog.String("error", err.Error()))
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = finishJob(ctx, tx, op, job, entities.JobDone, ""); err != nil {
+		return err
+	}
+
+	// Выражения SET видят значения строки до изменения, поэтому точность даты проверяет старую дату
+	query := `UPDATE songs SET
+				  release_date = CASE WHEN $2::date IS NOT NULL AND ($6 OR release_date IS NULL)
+					  THEN $2::date ELSE release_date END,
+				  release_date_precision = CASE WHEN $2::date IS NOT NULL AND ($6 OR release_date IS NULL)
+					  THEN $3 ELSE release_date_precision END,
+				  text = CASE WHEN $4 <> '' AND ($6 OR coalesce(text, '') = '') THEN $4 ELSE text END,
+				  link = CASE WHEN $5 <> '' AND ($6 OR coalesce(link, '') = '') THEN $5 ELSE link END,
+				  enrichment_status = 'done', version = version + 1
+			  WHERE id = $1`
+
+	if _, err = tx.ExecContext(ctx, query,
+		job.SongID,
+		releaseDate,
+		precision,
+		song.Text,
+		song.Link,
+		job.Overwrite,
+	); err != nil {
+		slog.Error(op, "Ошибка изменения данных", slog.String("error", err.Error()))
+		return err
+	}
+	return tx.Commit()
+}
+
+// RescheduleJob возвращает задачу в очередь после неудачной попытки, следующая попытка не раньше runAt.
+// Если задачу за это время забрал другой воркер, возвращается entities.ErrConflict.
+func (r *enrichmentRepository) RescheduleJob(
+	ctx context.Context,
+	job *entities.EnrichmentJob,
+	lastError string,
+	runAt time.Time,
+) error {
+	const op = "internal.repository.RescheduleJob"
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	query := `UPDATE enrichment_jobs SET status = 'pending', last_error = $2, run_at = $3,
+				  locked_until = NULL, updated_at = now()
+			  WHERE id = $1 AND status = 'running' AND lease_token = $4`
+
+	res, err := r.db.ExecContext(ctx, query, job.ID, lastError, runAt, job.LeaseToken)
+	if err != nil {
+		slog.Error(op, "Ошибка изменения задачи", slog.String("error", err.Error()))
+		return err
+	}
+	return checkAffected(op, res, jobLost(job.ID))
+}
+
+// FailJob завершает задачу с ошибкой после последней попытки, песня получает статус failed.
+// Если задачу за это время забрал другой воркер, возвращается entities.ErrConflict.
+func (r *enrichmentRepository) FailJob(ctx context.Context, job *entities.EnrichmentJob, lastError string) error {
+	const op = "internal.repository.FailJob"
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Error(op, "Ошибка начала транзакции", slog.String("error", err.Error()))
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = finishJob(ctx, tx, op, job, entities.JobFailed, lastError); err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, `UPDATE songs SET enrichment_status = 'failed' WHERE id = $1`, job.SongID); err != nil {
+		slog.Error(op, "Ошибка изменения данных", slog.String("error", err.Error()))
+		return err
+	}
+	return tx.Commit()
+}
+
+// finishJob переводит выполняемую воркером задачу в конечное состояние status
+func finishJob(
+	ctx context.Context,
+	tx *sql.Tx,
+	op string,
+	job *entities.EnrichmentJob,
+	status entities.JobStatus,
+	lastError string,
+) error {
+	query := `UPDATE enrichment_jobs SET status = $2, last_error = nullif($3, ''), locked_until = NULL, updated_at = now()
+			  WHERE id = $1 AND status = 'running' AND lease_token = $4`
+
+	res, err := tx.ExecContext(ctx, query, job.ID, status, lastError, job.LeaseToken)
+	if err != nil {
+		slog.Error(op, "Ошибка изменения задачи", slog.String("error", err.Error()))
+		return err
+	}
+	return checkAffected(op, res, jobLost(job.ID))
+}
+
+// jobLost ошибка для задачи, которую после истечения срока забрал другой воркер: её LeaseToken сменился
+func jobLost(id int) error {
+	return fmt.Errorf("задача обогащения %d уже не выполняется этим воркером: %w", id, entities.ErrConflict)
+}
+
+func (r *enrichmentRepository) GetSongEnrichment(ctx context.Context, songID int) (*entities.SongEnrichment, error) {
+	const op = "internal.repository.GetSongEnrichment"
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	enrichment := entities.SongEnrichment{SongID: songID}
+	query := `SELECT enrichment_status FROM songs WHERE id = $1 AND deleted_at IS NULL`
+	if err := r.db.QueryRowContext(ctx, query, songID).Scan(&enrichment.Status); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, songNotFound(songID)
+		}
+		slog.Error(op, "Ошибка запроса к DB", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	query = `SELECT ` + jobColumns + jobFrom + ` WHERE j.song_id = $1 ORDER BY j.id DESC LIMIT 1`
+	job, err := scanJob(r.db.QueryRowContext(ctx, query, songID))
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		slog.Error(op, "Ошибка запроса к DB", slog.String("error", err.Error()))
+		return nil, err
+	default:
+		enrichment.Job = job
+	}
+	return &enrichment, nil
+}
+
+func (r *enrichmentRepository) ListFailedJobs(ctx context.Context, limit, offset int) ([]entities.EnrichmentJob, error) {
+	const op = "internal.repository.ListFailedJobs"
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	query := `SELECT ` + jobColumns + jobFrom + `
+			  WHERE j.status = 'failed' ORDER BY j.updated_at DESC, j.id DESC LIMIT $1 OFFSET $2`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		slog.Error(op, "Ошибка запроса к DB", slog.String("error", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := make([]entities.EnrichmentJob, 0)
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			slog.Error(op, "Ошибка сканирования результата", slog.String("error", err.Error()))
+			return nil, err
+		}
+		jobs = append(jobs, *job)
+	}
+	return jobs, rows.Err()
+}
+
+// RetryJob возвращает завершившуюся ошибкой задачу в очередь с обнулённым счётчиком попыток.
+// Задачу в другом состоянии или задачу песни, у которой уже есть незавершённая задача, повторить нельзя.
+func (r *enrichmentRepository) RetryJob(ctx context.Context, id int) (*entities.EnrichmentJob, error) {
+	const op = "internal.repository.RetryJob"
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Error(op, "Ошибка начала транзакции", slog.String("error", err.Error()))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE enrichment_jobs SET status = 'pending', attempts = 0, run_at = now(), updated_at = now()
+			  WHERE id = $1 AND status = 'failed' RETURNING song_id`
+
+	var songID int
+	if err = tx.QueryRowContext(ctx, query, id).Scan(&songID); err != nil {
+		if isConstraintViolation(err, "23505") {
+			return nil, fmt.Errorf("у песни уже есть задача обогащения в очереди: %w", entities.ErrConflict)
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			slog.Error(op, "Ошибка изменения задачи", slog.String("error", err.Error()))
+			return nil, err
+		}
+
+		var exists bool
+		if err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM enrichment_jobs WHERE id = $1)`, id).Scan(&exists); err != nil {
+			slog.Error(op, "Ошибка запроса к DB", slog.String("error", err.Error()))
+			return nil, err
+		}
+		if !exists {
+			return nil, fmt.Errorf("задача обогащения %d: %w", id, entities.ErrNotFound)
+		}
+		return nil, fmt.Errorf("задача обогащения %d не завершилась ошибкой: %w", id, entities.ErrConflict)
+	}
+
+	if _, err = tx.ExecContext(ctx, `UPDATE songs SET enrichment_status = 'pending' WHERE id = $1`, songID); err != nil {
+		slog.Error(op, "Ошибка изменения данных", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	job, err := scanJob(tx.QueryRowContext(ctx, `SELECT `+jobColumns+jobFrom+` WHERE j.id = $1`, id))
+	if err != nil {
+		slog.Error(op, "Ошибка запроса к DB", slog.String("error", err.Error()))
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		slog.Error(op, "Ошибка фиксации транзакции", slog.String("error", err.Error()))
+		return nil, err
+	}
+	return job, nil
+}
+
+// scanJob сканирует строку, выбранную с jobColumns
+func scanJob(row interface{ Scan(dest ...any) error }) (*entities.EnrichmentJob, error) {
+	var job entities.EnrichmentJob
+	if err := row.Scan(
+		&job.ID,
+		&job.SongID,
+		&job.Group,
+		&job.Title,
+		&job.Status,
+		&job.Overwrite,
+		&job.Attempts,
+		&job.LastError,
+		&job.RunAt,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+		&job.LeaseToken,
+	); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
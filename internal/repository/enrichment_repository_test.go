@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"TestEffectiveMobile/internal/entities"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"os"
+	"testing"
+	"time"
+)
+
+// testDB подключается к отдельной базе для тестов из TEST_DB_DSN и применяет миграции.
+// Без TEST_DB_DSN тесты, которым нужна Postgres, пропускаются.
+func testDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DB_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DB_DSN не задан, тест с Postgres пропущен")
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	driver, err := postgres.WithInstance(db, &postgres.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := migrate.NewWithDatabaseInstance("file://../../migrations", "postgres", driver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestEnrichmentJobReclaimed(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	songs := NewSongRepository(db, 5*time.Second, time.Minute)
+	jobs := NewEnrichmentRepository(db, 5*time.Second)
+
+	group := fmt.Sprintf("Lease test %d", time.Now().UnixNano())
+	songID, err := songs.CreateSong(ctx, entities.Song{Group: group, Title: "Uprising", EnrichmentStatus: entities.EnrichmentPending})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Exec(`DELETE FROM songs WHERE id = $1`, songID)
+		db.Exec(`DELETE FROM artists WHERE name = $1`, group)
+	})
+
+	// claim забирает задачу этой песни. Отрицательный lease сразу отдаёт задачу следующему воркеру.
+	claim := func() *entities.EnrichmentJob {
+		t.Helper()
+		job, err := jobs.ClaimJob(ctx, -time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.SongID != songID {
+			t.Fatalf("получена задача песни %d, ожидалась %d: база для тестов должна быть пустой", job.SongID, songID)
+		}
+		return job
+	}
+	stale := claim()
+	current := claim()
+	if current.ID != stale.ID || current.LeaseToken == stale.LeaseToken {
+		t.Fatalf("повторная выдача задачи %+v после %+v", current, stale)
+	}
+
+	song := entities.Song{ID: songID, Text: "Paranoia is in bloom"}
+	if err = jobs.CompleteJob(ctx, stale, song); !errors.Is(err, entities.ErrConflict) {
+		t.Errorf("CompleteJob прежнего воркера: %v, ожидалась entities.ErrConflict", err)
+	}
+	if err = jobs.RescheduleJob(ctx, stale, "503", time.Now()); !errors.Is(err, entities.ErrConflict) {
+		t.Errorf("RescheduleJob прежнего воркера: %v, ожидалась entities.ErrConflict", err)
+	}
+	if err = jobs.FailJob(ctx, stale, "503"); !errors.Is(err, entities.ErrConflict) {
+		t.Errorf("FailJob прежнего воркера: %v, ожидалась entities.ErrConflict", err)
+	}
+
+	if err = jobs.CompleteJob(ctx, current, song); err != nil {
+		t.Fatalf("CompleteJob текущего воркера: %v", err)
+	}
+	enrichment, err := jobs.GetSongEnrichment(ctx, songID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if enrichment.Status != entities.EnrichmentDone || enrichment.Job == nil || enrichment.Job.Status != entities.JobDone {
+		t.Errorf("обогащение после завершения %+v", enrichment)
+	}
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/lib/pq"
+	"log/slog"
+)
+
+// isDuplicateSong сообщает, что запись нарушила уникальность группы и названия песни
+func isDuplicateSong(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == "idx_songs_artist_title_normalized"
+}
+
+// isConstraintViolation сообщает, что запрос нарушил ограничение DB с кодом code
+// (23505 уникальность, 23503 внешний ключ)
+func isConstraintViolation(err error, code pq.ErrorCode) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == code
+}
+
+// checkAffected возвращает notFound, если запрос не изменил ни одной строки
+func checkAffected(op string, res sql.Result, notFound error) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		slog.Error(op, "Ошибка получения количества строк", slog.String("error", err.Error()))
+		return err
+	}
+	if affected == 0 {
+		return notFound
+	}
+	return nil
+}
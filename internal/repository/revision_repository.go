@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"TestEffectiveMobile/internal/entities"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+type RevisionRepository interface {
+	ListRevisions(ctx context.Context, songID, limit, offset int) ([]entities.SongRevision, error)
+	GetRevision(ctx context.Context, songID, revision int) (*entities.SongRevision, error)
+}
+
+type revisionRepository struct {
+	db      *sql.DB
+	timeout time.Duration
+}
+
+// NewRevisionRepository создаёт репозиторий, queryTimeout ограничивает время каждого запроса к DB
+func NewRevisionRepository(db *sql.DB, queryTimeout time.Duration) RevisionRepository {
+	return &revisionRepository{
+		db:      db,
+		timeout: queryTimeout,
+	}
+}
+
+// revisionColumns поля ревизии и снимка песни, извлекаемые из JSONB
+const revisionColumns = `song_id, revision, operation, created_at, group_name,
+				  (data->>'artist_id')::int,
+				  data->>'song_title',
+				  coalesce(CASE data->>'release_date_precision'
+					  WHEN 'year' THEN left(data->>'release_date', 4)
+					  WHEN 'month' THEN left(data->>'release_date', 7)
+					  ELSE data->>'release_date' END, ''),
+				  coalesce(data->>'release_date_precision', ''),
+				  coalesce(data->>'text', ''),
+				  coalesce(data->>'link', '')`
+
+func (r *revisionRepository) ListRevisions(ctx context.Context, songID, limit, offset int) ([]entities.SongRevision, error) {
+	const op = "internal.repository.ListRevisions"
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	query := `SELECT ` + revisionColumns + `
+			  FROM song_revisions WHERE song_id = $1
+			  ORDER BY revision DESC LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.QueryContext(ctx, query, songID, limit, offset)
+	if err != nil {
+		slog.Error(op, "Ошибка запроса к DB", slog.String("error", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := make([]entities.SongRevision, 0)
+	for rows.Next() {
+		revision, err := scanRevision(rows)
+		if err != nil {
+			slog.Error(op, "Ошибка сканирования результата", slog.String("error", err.Error()))
+			return nil, err
+		}
+		revisions = append(revisions, *revision)
+	}
+	if err = rows.Err(); err != nil {
+		slog.Error(op, "Ошибка чтения результата", slog.String("error", err.Error()))
+		return nil, err
+	}
+	return revisions, nil
+}
+
+func (r *revisionRepository) GetRevision(ctx context.Context, songID, revision int) (*entities.SongRevision, error) {
+	const op = "internal.repository.GetRevision"
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	query := `SELECT ` + revisionColumns + `
+			  FROM song_revisions WHERE song_id = $1 AND revision = $2`
+
+	rev, err := scanRevision(r.db.QueryRowContext(ctx, query, songID, revision))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("ревизия %d песни %d: %w", revision, songID, entities.ErrNotFound)
+		}
+		slog.Error(op, "Ошибка парсинга данных", slog.String("error", err.Error()))
+		return nil, err
+	}
+	return rev, nil
+}
+
+// scanRevision сканирует строку, выбранную с revisionColumns
+func scanRevision(row interface{ Scan(dest ...any) error }) (*entities.SongRevision, error) {
+	var revision entities.SongRevision
+	if err := row.Scan(
+		&revision.SongID,
+		&revision.Revision,
+		&revision.Operation,
+		&revision.CreatedAt,
+		&revision.Snapshot.Group,
+		&revision.Snapshot.ArtistID,
+		&revision.Snapshot.Title,
+		&revision.Snapshot.ReleaseDate,
+		&revision.Snapshot.ReleaseDatePrecision,
+		&revision.Snapshot.Text,
+		&revision.Snapshot.Link,
+	); err != nil {
+		return nil, err
+	}
+	revision.Snapshot.ID = revision.SongID
+	return &revision, nil
+}
@@ -0,0 +1,270 @@
+package repository
+
+import (
+	"TestEffectiveMobile/internal/entities"
+	"fmt"
+	"github.com/lib/pq"
+	"strconv"
+	"strings"
+)
+
+// songFilterColumns выражения SQL и типы для полей фильтра
+var songFilterColumns = map[entities.FilterField]struct {
+	expr    string
+	sqlType string
+}{
+	entities.FieldID:          {"s.id", "int"},
+	entities.FieldArtistID:    {"s.artist_id", "int"},
+	entities.FieldGroup:       {"a.name", "text"},
+	entities.FieldTitle:       {"s.song_title", "text"},
+	entities.FieldReleaseDate: {"s.release_date", "date"},
+	entities.FieldReleaseYear: {"date_part('year', s.release_date)::int", "int"},
+	entities.FieldText:        {"s.text", "text"},
+	entities.FieldLink:        {"s.link", "text"},
+}
+
+// songFilterComparisons операторы сравнения для операций фильтра с одним значением
+var songFilterComparisons = map[entities.FilterOp]string{
+	entities.OpEq:  "=",
+	entities.OpNe:  "IS DISTINCT FROM",
+	entities.OpGt:  ">",
+	entities.OpGte: ">=",
+	entities.OpLt:  "<",
+	entities.OpLte: "<=",
+}
+
+// likeEscaper экранирует спецсимволы шаблона LIKE
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// releaseDateSelect выводит дату выпуска с учётом её точности: 2006, 2006-07 или 2006-07-16
+const releaseDateSelect = `coalesce(CASE s.release_date_precision
+				  WHEN 'year' THEN to_char(s.release_date, 'YYYY')
+				  WHEN 'month' THEN to_char(s.release_date, 'YYYY-MM')
+				  ELSE to_char(s.release_date, 'YYYY-MM-DD') END, '')`
+
+// orderKey ключ сортировки списка песен
+type orderKey struct {
+	// name имя ключа в сигнатуре сортировки курсора
+	name string
+	// expr выражение SQL, по которому сортируются песни
+	expr string
+	desc bool
+	// nullable ключ может быть NULL, такие песни идут в конце
+	nullable bool
+	// value возвращает значение ключа у песни для курсора, nil для NULL
+	value func(song *entities.Song) *string
+}
+
+// songSortKeys ключи сортировки для полей из белого списка entities.SortField
+var songSortKeys = map[entities.SortField]orderKey{
+	entities.SortByID: {
+		name: string(entities.SortByID),
+		expr: "s.id",
+		value: func(song *entities.Song) *string {
+			value := strconv.Itoa(song.ID)
+			return &value
+		},
+	},
+	entities.SortByGroup: {
+		name: string(entities.SortByGroup),
+		expr: "a.name",
+		value: func(song *entities.Song) *string {
+			return &song.Group
+		},
+	},
+	entities.SortByTitle: {
+		name: string(entities.SortByTitle),
+		expr: "s.song_title",
+		value: func(song *entities.Song) *string {
+			return &song.Title
+		},
+	},
+	entities.SortByReleaseDate: {
+		name:     string(entities.SortByReleaseDate),
+		expr:     "s.release_date",
+		nullable: true,
+		value: func(song *entities.Song) *string {
+			date, err := entities.ParseReleaseDate(song.ReleaseDate)
+			if err != nil {
+				return nil
+			}
+			value := date.Date.Format("2006-01-02")
+			return &value
+		},
+	},
+}
+
+// songListQuery части запроса списка песен, общие для обоих режимов пагинации
+type songListQuery struct {
+	where string
+	args  []interface{}
+	// score выражение коэффициента сходства, пустое, если нечётких фильтров нет
+	score string
+	// keys ключи сортировки, песни с равными ключами упорядочены по id
+	keys []orderKey
+	// omitText не выбирать текст песни
+	omitText bool
+}
+
+// addArg добавляет параметр запроса и возвращает его номер
+func (q *songListQuery) addArg(arg interface{}) int {
+	q.args = append(q.args, arg)
+	return len(q.args)
+}
+
+// buildSongListQuery переводит фильтр в условия WHERE с параметрами, а сортировку в ключи
+func buildSongListQuery(filter entities.SongFilter, sort entities.SongSort) (*songListQuery, error) {
+	q := &songListQuery{args: make([]interface{}, 0)}
+	similarity := make([]string, 0)
+
+	for _, cond := range filter.Conditions {
+		column, ok := songFilterColumns[cond.Field]
+		if !ok {
+			return nil, fmt.Errorf("%w: фильтрация по полю %q недоступна", entities.ErrValidation, cond.Field)
+		}
+
+		switch cond.Op {
+		case entities.OpIsNull:
+			q.where += fmt.Sprintf(" AND %s IS NULL", column.expr)
+		case entities.OpNotNull:
+			q.where += fmt.Sprintf(" AND %s IS NOT NULL", column.expr)
+		case entities.OpIn:
+			q.where += fmt.Sprintf(" AND %s = ANY($%d::%s[])", column.expr, q.addArg(pq.Array(cond.Values)), column.sqlType)
+		case entities.OpPrefix:
+			q.where += fmt.Sprintf(" AND %s ILIKE $%d || '%%'", column.expr, q.addArg(likeEscaper.Replace(cond.Values[0])))
+		case entities.OpContains:
+			q.where += fmt.Sprintf(" AND %s ILIKE '%%' || $%d || '%%'", column.expr, q.addArg(likeEscaper.Replace(cond.Values[0])))
+		case entities.OpFuzzy:
+			i := q.addArg(cond.Values[0])
+			q.where += fmt.Sprintf(" AND %s %% $%d", column.expr, i)
+			similarity = append(similarity, fmt.Sprintf("similarity(%s, $%d)", column.expr, i))
+		default:
+			cmp, ok := songFilterComparisons[cond.Op]
+			if !ok || len(cond.Values) != 1 {
+				return nil, fmt.Errorf("%w: неверное условие фильтра %q для поля %q", entities.ErrValidation, cond.Op, cond.Field)
+			}
+			q.where += fmt.Sprintf(" AND %s %s $%d::%s", column.expr, cmp, q.addArg(cond.Values[0]), column.sqlType)
+		}
+	}
+
+	// Для нечётких фильтров считаем средний коэффициент сходства
+	if len(similarity) > 0 {
+		q.score = fmt.Sprintf("(%s) / %d", strings.Join(similarity, " + "), len(similarity))
+	}
+
+	// Явная сортировка важнее сортировки по сходству
+	for _, sortKey := range sort {
+		key, ok := songSortKeys[sortKey.Field]
+		if !ok {
+			return nil, fmt.Errorf("%w: сортировка по полю %q недоступна", entities.ErrValidation, sortKey.Field)
+		}
+		key.desc = sortKey.Desc
+		q.keys = append(q.keys, key)
+	}
+	if len(q.keys) == 0 && q.score != "" {
+		q.keys = append(q.keys, orderKey{
+			name: "score",
+			expr: q.score,
+			desc: true,
+			value: func(song *entities.Song) *string {
+				if song.Similarity == nil {
+					return nil
+				}
+				value := strconv.FormatFloat(*song.Similarity, 'g', -1, 64)
+				return &value
+			},
+		})
+	}
+	return q, nil
+}
+
+// sql возвращает запрос списка песен с заданным хвостом (ORDER BY, LIMIT и т.д.)
+func (q *songListQuery) sql(tail string) string {
+	score := "NULL::float8"
+	if q.score != "" {
+		score = q.score
+	}
+	text := "s.text"
+	if q.omitText {
+		text = "''"
+	}
+	return fmt.Sprintf(`SELECT s.id, s.artist_id, a.name, s.song_title, %s, coalesce(s.release_date_precision, ''),
+				  %s, s.link, s.version, s.enrichment_status, %s AS score, s.deleted_at
+			  FROM songs s JOIN artists a ON a.id = s.artist_id WHERE s.deleted_at IS NULL%s %s`,
+		releaseDateSelect, text, score, q.where, tail)
+}
+
+// sortKey возвращает сигнатуру сортировки, на которую опирается курсор
+func (q *songListQuery) sortKey() string {
+	if len(q.keys) == 0 {
+		return "id"
+	}
+	names := make([]string, 0, len(q.keys))
+	for _, key := range q.keys {
+		if key.desc {
+			names = append(names, "-"+key.name)
+		} else {
+			names = append(names, key.name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// order возвращает выражение ORDER BY, песни с равными ключами упорядочены по id
+func (q *songListQuery) order() string {
+	parts := make([]string, 0, len(q.keys)+1)
+	for _, key := range q.keys {
+		direction := "ASC"
+		if key.desc {
+			direction = "DESC"
+		}
+		parts = append(parts, fmt.Sprintf("%s %s NULLS LAST", key.expr, direction))
+	}
+	parts = append(parts, "s.id")
+	return strings.Join(parts, ", ")
+}
+
+// after добавляет условие, отбирающее песни строго после позиции курсора
+func (q *songListQuery) after(cursor *entities.Cursor) error {
+	if cursor.Sort != q.sortKey() || len(cursor.Values) != len(q.keys) {
+		return fmt.Errorf("%w: курсор не соответствует порядку сортировки %q", entities.ErrValidation, q.sortKey())
+	}
+
+	// Позиция после курсора: первые ключи равны, а очередной ключ больше (или меньше при DESC)
+	branches := make([]string, 0, len(q.keys)+1)
+	equal := make([]string, 0, len(q.keys)+1)
+	for i, key := range q.keys {
+		value := cursor.Values[i]
+		if value == nil {
+			// После NULL при NULLS LAST идут только другие NULL
+			equal = append(equal, fmt.Sprintf("(%s) IS NULL", key.expr))
+			continue
+		}
+
+		n := q.addArg(*value)
+		cmp := ">"
+		if key.desc {
+			cmp = "<"
+		}
+		next := fmt.Sprintf("(%s) %s $%d", key.expr, cmp, n)
+		if key.nullable {
+			next = fmt.Sprintf("(%s OR (%s) IS NULL)", next, key.expr)
+		}
+		branches = append(branches, strings.Join(append(equal[:len(equal):len(equal)], next), " AND "))
+		equal = append(equal, fmt.Sprintf("(%s) = $%d", key.expr, n))
+	}
+	equal = append(equal, fmt.Sprintf("s.id > $%d", q.addArg(cursor.ID)))
+	branches = append(branches, strings.Join(equal, " AND "))
+
+	q.where += " AND ((" + strings.Join(branches, ") OR (") + "))"
+	return nil
+}
+
+// cursor возвращает курсор, указывающий на песню song
+func (q *songListQuery) cursor(song *entities.Song) *entities.Cursor {
+	cursor := entities.NewCursor(q.sortKey(), song.ID)
+	for _, key := range q.keys {
+		cursor.Values = append(cursor.Values, key.value(song))
+	}
+	return cursor
+}
@@ -0,0 +1,306 @@
+package repository
+
+import (
+	"TestEffectiveMobile/internal/entities"
+	"errors"
+	"github.com/lib/pq"
+	"reflect"
+	"testing"
+)
+
+func TestBuildSongListQuery(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions []entities.FilterCondition
+		sort       string
+		wantWhere  string
+		wantArgs   []interface{}
+		wantScore  string
+		wantOrder  string
+		wantSort   string
+		wantErr    bool
+	}{
+		{
+			name:      "без фильтра и сортировки",
+			wantArgs:  []interface{}{},
+			wantOrder: "s.id",
+			wantSort:  "id",
+		},
+		{
+			name: "точные сравнения",
+			conditions: []entities.FilterCondition{
+				{Field: entities.FieldGroup, Op: entities.OpEq, Values: []string{"Muse"}},
+				{Field: entities.FieldReleaseYear, Op: entities.OpGte, Values: []string{"2000"}},
+				{Field: entities.FieldLink, Op: entities.OpNe, Values: []string{""}},
+			},
+			wantWhere: " AND a.name = $1::text AND date_part('year', s.release_date)::int >= $2::int AND s.link IS DISTINCT FROM $3::text",
+			wantArgs:  []interface{}{"Muse", "2000", ""},
+			wantOrder: "s.id",
+			wantSort:  "id",
+		},
+		{
+			name: "in и проверки NULL",
+			conditions: []entities.FilterCondition{
+				{Field: entities.FieldID, Op: entities.OpIn, Values: []string{"1", "2"}},
+				{Field: entities.FieldReleaseDate, Op: entities.OpIsNull},
+				{Field: entities.FieldText, Op: entities.OpNotNull},
+			},
+			wantWhere: " AND s.id = ANY($1::int[]) AND s.release_date IS NULL AND s.text IS NOT NULL",
+			wantArgs:  []interface{}{pq.Array([]string{"1", "2"})},
+			wantOrder: "s.id",
+			wantSort:  "id",
+		},
+		{
+			name: "prefix и contains экранируют шаблон LIKE",
+			conditions: []entities.FilterCondition{
+				{Field: entities.FieldTitle, Op: entities.OpPrefix, Values: []string{"100%"}},
+				{Field: entities.FieldText, Op: entities.OpContains, Values: []string{`a_b\c`}},
+			},
+			wantWhere: ` AND s.song_title ILIKE $1 || '%' AND s.text ILIKE '%' || $2 || '%'`,
+			wantArgs:  []interface{}{`100\%`, `a\_b\\c`},
+			wantOrder: "s.id",
+			wantSort:  "id",
+		},
+		{
+			name: "fuzzy сортирует по сходству",
+			conditions: []entities.FilterCondition{
+				{Field: entities.FieldGroup, Op: entities.OpFuzzy, Values: []string{"Muse"}},
+				{Field: entities.FieldTitle, Op: entities.OpFuzzy, Values: []string{"Uprsing"}},
+			},
+			wantWhere: " AND a.name % $1 AND s.song_title % $2",
+			wantArgs:  []interface{}{"Muse", "Uprsing"},
+			wantScore: "(similarity(a.name, $1) + similarity(s.song_title, $2)) / 2",
+			wantOrder: "(similarity(a.name, $1) + similarity(s.song_title, $2)) / 2 DESC NULLS LAST, s.id",
+			wantSort:  "-score",
+		},
+		{
+			name: "явная сортировка важнее сходства",
+			conditions: []entities.FilterCondition{
+				{Field: entities.FieldGroup, Op: entities.OpFuzzy, Values: []string{"Muse"}},
+			},
+			sort:      "title",
+			wantWhere: " AND a.name % $1",
+			wantArgs:  []interface{}{"Muse"},
+			wantScore: "(similarity(a.name, $1)) / 1",
+			wantOrder: "s.song_title ASC NULLS LAST, s.id",
+			wantSort:  "title",
+		},
+		{
+			name:      "несколько ключей сортировки",
+			sort:      "group,-release_date",
+			wantArgs:  []interface{}{},
+			wantOrder: "a.name ASC NULLS LAST, s.release_date DESC NULLS LAST, s.id",
+			wantSort:  "group,-release_date",
+		},
+		{
+			name:       "неизвестное поле фильтра",
+			conditions: []entities.FilterCondition{{Field: "deleted_at", Op: entities.OpIsNull}},
+			wantErr:    true,
+		},
+		{
+			name:       "сравнение с несколькими значениями",
+			conditions: []entities.FilterCondition{{Field: entities.FieldID, Op: entities.OpGt, Values: []string{"1", "2"}}},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sort, err := entities.ParseSongSort(tt.sort)
+			if err != nil {
+				t.Fatalf("ParseSongSort(%q): %v", tt.sort, err)
+			}
+
+			q, err := buildSongListQuery(entities.SongFilter{Conditions: tt.conditions}, sort)
+			if tt.wantErr {
+				if !errors.Is(err, entities.ErrValidation) {
+					t.Fatalf("ошибка %v, ожидалась entities.ErrValidation", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if q.where != tt.wantWhere {
+				t.Errorf("where = %q, ожидалось %q", q.where, tt.wantWhere)
+			}
+			if !reflect.DeepEqual(q.args, tt.wantArgs) {
+				t.Errorf("args = %#v, ожидалось %#v", q.args, tt.wantArgs)
+			}
+			if q.score != tt.wantScore {
+				t.Errorf("score = %q, ожидалось %q", q.score, tt.wantScore)
+			}
+			if order := q.order(); order != tt.wantOrder {
+				t.Errorf("order() = %q, ожидалось %q", order, tt.wantOrder)
+			}
+			if sortKey := q.sortKey(); sortKey != tt.wantSort {
+				t.Errorf("sortKey() = %q, ожидалось %q", sortKey, tt.wantSort)
+			}
+		})
+	}
+}
+
+func TestSongListQueryAfter(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions []entities.FilterCondition
+		sort       string
+		cursor     entities.Cursor
+		wantWhere  string
+		wantArgs   []interface{}
+		wantErr    bool
+	}{
+		{
+			name:      "порядок по умолчанию",
+			cursor:    entities.Cursor{Sort: "id", ID: 5},
+			wantWhere: " AND ((s.id > $1))",
+			wantArgs:  []interface{}{5},
+		},
+		{
+			name:      "ключ по возрастанию",
+			sort:      "group",
+			cursor:    entities.Cursor{Sort: "group", Values: []*string{strPtr("Muse")}, ID: 3},
+			wantWhere: " AND (((a.name) > $1) OR ((a.name) = $1 AND s.id > $2))",
+			wantArgs:  []interface{}{"Muse", 3},
+		},
+		{
+			name:   "ключ с NULL по убыванию после фильтра",
+			sort:   "-release_date",
+			cursor: entities.Cursor{Sort: "-release_date", Values: []*string{strPtr("2006-07-16")}, ID: 8},
+			conditions: []entities.FilterCondition{
+				{Field: entities.FieldGroup, Op: entities.OpEq, Values: []string{"Muse"}},
+			},
+			wantWhere: " AND a.name = $1::text AND ((((s.release_date) < $2 OR (s.release_date) IS NULL))" +
+				" OR ((s.release_date) = $2 AND s.id > $3))",
+			wantArgs: []interface{}{"Muse", "2006-07-16", 8},
+		},
+		{
+			name:      "курсор на песне без даты",
+			sort:      "release_date",
+			cursor:    entities.Cursor{Sort: "release_date", Values: []*string{nil}, ID: 4},
+			wantWhere: " AND (((s.release_date) IS NULL AND s.id > $1))",
+			wantArgs:  []interface{}{4},
+		},
+		{
+			name:   "два ключа",
+			sort:   "group,-title",
+			cursor: entities.Cursor{Sort: "group,-title", Values: []*string{strPtr("Muse"), strPtr("Uprising")}, ID: 2},
+			wantWhere: " AND (((a.name) > $1) OR ((a.name) = $1 AND (s.song_title) < $2)" +
+				" OR ((a.name) = $1 AND (s.song_title) = $2 AND s.id > $3))",
+			wantArgs: []interface{}{"Muse", "Uprising", 2},
+		},
+		{
+			name:    "курсор другой сортировки",
+			sort:    "group",
+			cursor:  entities.Cursor{Sort: "id", ID: 1},
+			wantErr: true,
+		},
+		{
+			name:    "неверное число значений",
+			sort:    "group",
+			cursor:  entities.Cursor{Sort: "group", ID: 1},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sort, err := entities.ParseSongSort(tt.sort)
+			if err != nil {
+				t.Fatal(err)
+			}
+			q, err := buildSongListQuery(entities.SongFilter{Conditions: tt.conditions}, sort)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = q.after(&tt.cursor)
+			if tt.wantErr {
+				if !errors.Is(err, entities.ErrValidation) {
+					t.Fatalf("ошибка %v, ожидалась entities.ErrValidation", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if q.where != tt.wantWhere {
+				t.Errorf("where = %q, ожидалось %q", q.where, tt.wantWhere)
+			}
+			if !reflect.DeepEqual(q.args, tt.wantArgs) {
+				t.Errorf("args = %#v, ожидалось %#v", q.args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestSongListQueryCursorRoundTrip(t *testing.T) {
+	similarity := 0.75
+
+	tests := []struct {
+		name       string
+		conditions []entities.FilterCondition
+		sort       string
+		song       entities.Song
+		wantValues []*string
+	}{
+		{
+			name: "порядок по умолчанию",
+			song: entities.Song{ID: 9},
+		},
+		{
+			name:       "дата выпуска в любом формате",
+			sort:       "group,-release_date",
+			song:       entities.Song{ID: 9, Group: "Muse", ReleaseDate: "16.07.2006"},
+			wantValues: []*string{strPtr("Muse"), strPtr("2006-07-16")},
+		},
+		{
+			name:       "песня без даты",
+			sort:       "release_date",
+			song:       entities.Song{ID: 9},
+			wantValues: []*string{nil},
+		},
+		{
+			name: "сходство",
+			conditions: []entities.FilterCondition{
+				{Field: entities.FieldTitle, Op: entities.OpFuzzy, Values: []string{"Uprsing"}},
+			},
+			song:       entities.Song{ID: 9, Similarity: &similarity},
+			wantValues: []*string{strPtr("0.75")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sort, err := entities.ParseSongSort(tt.sort)
+			if err != nil {
+				t.Fatal(err)
+			}
+			build := func() *songListQuery {
+				q, err := buildSongListQuery(entities.SongFilter{Conditions: tt.conditions}, sort)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return q
+			}
+
+			// Курсор проходит через клиента в виде строки и должен подойти к тому же запросу
+			cursor, err := entities.DecodeCursor(build().cursor(&tt.song).Encode())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cursor.ID != tt.song.ID || !reflect.DeepEqual(cursor.Values, tt.wantValues) {
+				t.Errorf("курсор %+v, ожидались id %d и значения %v", cursor, tt.song.ID, tt.wantValues)
+			}
+			if err = build().after(cursor); err != nil {
+				t.Errorf("after(): %v", err)
+			}
+		})
+	}
+}
+
+// strPtr возвращает указатель на строку для значений курсора
+func strPtr(s string) *string {
+	return &s
+}
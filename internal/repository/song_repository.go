@@ -2,131 +2,836 @@ package repository
 
 import (
 	"TestEffectiveMobile/internal/entities"
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"github.com/lib/pq"
 	"log/slog"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type SongRepository interface {
-	ListSongs(filter map[string]string, limit, offset int) ([]entities.Song, error)
-	DeleteSong(id int) error
-	UpdateSong(song entities.Song) error
-	CreateSong(song entities.Song) (int, error)
-	GetSongByID(id int) (*entities.Song, error)
+	ListSongs(ctx context.Context, filter entities.SongFilter, sort entities.SongSort, limit, offset int) ([]entities.Song, error)
+	ListSongsAfter(
+		ctx context.Context,
+		filter entities.SongFilter,
+		sort entities.SongSort,
+		cursor *entities.Cursor,
+		limit int,
+	) ([]entities.Song, *entities.Cursor, error)
+	CountSongs(ctx context.Context, filter entities.SongFilter) (int, error)
+	ExportSongs(
+		ctx context.Context,
+		filter entities.SongFilter,
+		sort entities.SongSort,
+		withText bool,
+		fn func(song entities.Song) error,
+	) error
+	DeleteSong(ctx context.Context, id, version int) error
+	ListTrash(ctx context.Context, limit, offset int) ([]entities.Song, error)
+	RestoreSong(ctx context.Context, id int) error
+	PurgeTrash(ctx context.Context, before time.Time) (int64, error)
+	UpdateSong(ctx context.Context, song entities.Song) error
+	PatchSong(ctx context.Context, id int, patch entities.SongPatch, version int) error
+	CreateSong(ctx context.Context, song entities.Song) (int, error)
+	EnqueueEnrichment(ctx context.Context, id int, overwrite bool) error
+	GetSongByID(ctx context.Context, id int) (*entities.Song, error)
+	CountRevisions(ctx context.Context, id int) (int, error)
+	FindSong(ctx context.Context, group, title string) (*entities.Song, error)
+	ListDuplicates(ctx context.Context, threshold float64, limit, offset int) ([]entities.SongDuplicate, error)
+	MergeSongs(ctx context.Context, targetID, sourceID, version int) error
+	SearchSongs(ctx context.Context, q string, limit, offset int) ([]entities.SongSearchResult, error)
 }
 
 type songRepository struct {
-	db *sql.DB
+	db            *sql.DB
+	timeout       time.Duration
+	exportTimeout time.Duration
 }
 
-func NewSongRepository(db *sql.DB) SongRepository {
+// NewSongRepository создаёт репозиторий песен. queryTimeout ограничивает время каждого запроса к DB,
+// exportTimeout время выгрузки всей библиотеки.
+func NewSongRepository(db *sql.DB, queryTimeout, exportTimeout time.Duration) SongRepository {
 	return &songRepository{
-		db: db,
+		db:            db,
+		timeout:       queryTimeout,
+		exportTimeout: exportTimeout,
 	}
 }
 
-func (r *songRepository) ListSongs(filter map[string]string, limit, offset int) ([]entities.Song, error) {
+func (r *songRepository) ListSongs(
+	ctx context.Context,
+	filter entities.SongFilter,
+	sort entities.SongSort,
+	limit, offset int,
+) ([]entities.Song, error) {
 	const op = "internal.repository.ListSongs"
 
-	query := `SELECT id, group_name, song_title, release_date, text, link FROM songs WHERE 1=1`
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
 
 	// Формируем строку запроса к DB
-	args := make([]interface{}, 0)
-	i := 1
-	for key, value := range filter {
-		query += fmt.Sprintf(" AND %s=$%d", key, i)
-		args = append(args, value)
-		i++
+	q, err := buildSongListQuery(filter, sort)
+	if err != nil {
+		slog.Error(op, "Ошибка формирования запроса", slog.String("error", err.Error()))
+		return nil, err
+	}
+	query := q.sql(fmt.Sprintf("ORDER BY %s LIMIT $%d OFFSET $%d", q.order(), q.addArg(limit), q.addArg(offset)))
+
+	return r.querySongs(ctx, op, query, q.args...)
+}
+
+func (r *songRepository) ListSongsAfter(
+	ctx context.Context,
+	filter entities.SongFilter,
+	sort entities.SongSort,
+	cursor *entities.Cursor,
+	limit int,
+) ([]entities.Song, *entities.Cursor, error) {
+	const op = "internal.repository.ListSongsAfter"
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	q, err := buildSongListQuery(filter, sort)
+	if err != nil {
+		slog.Error(op, "Ошибка формирования запроса", slog.String("error", err.Error()))
+		return nil, nil, err
+	}
+
+	// Продолжаем выборку строго после последней записи предыдущей страницы
+	if cursor != nil {
+		if err = q.after(cursor); err != nil {
+			slog.Error(op, "Ошибка формирования запроса", slog.String("error", err.Error()))
+			return nil, nil, err
+		}
+	}
+
+	// Запрашиваем на одну запись больше, чтобы понять, есть ли следующая страница
+	query := q.sql(fmt.Sprintf("ORDER BY %s LIMIT $%d", q.order(), q.addArg(limit+1)))
+
+	songs, err := r.querySongs(ctx, op, query, q.args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(songs) <= limit {
+		return songs, nil, nil
+	}
+
+	songs = songs[:limit]
+	return songs, q.cursor(&songs[len(songs)-1]), nil
+}
+
+func (r *songRepository) CountSongs(ctx context.Context, filter entities.SongFilter) (int, error) {
+	const op = "internal.repository.CountSongs"
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	q, err := buildSongListQuery(filter, nil)
+	if err != nil {
+		slog.Error(op, "Ошибка формирования запроса", slog.String("error", err.Error()))
+		return 0, err
+	}
+	query := `SELECT count(*) FROM songs s JOIN artists a ON a.id = s.artist_id WHERE s.deleted_at IS NULL` + q.where
+
+	var total int
+	if err = r.db.QueryRowContext(ctx, query, q.args...).Scan(&total); err != nil {
+		slog.Error(op, "Ошибка запроса к DB", slog.String("error", err.Error()))
+		return 0, err
 	}
-	query += fmt.Sprintf(" ORDER BY id LIMIT $%d OFFSET $%d", i, i+1)
-	args = append(args, limit, offset)
+	return total, nil
+}
+
+// exportBatchSize количество песен, читаемых из курсора DB за один раз
+const exportBatchSize = 500
+
+// ExportSongs передаёт в fn все песни, подходящие под фильтр, читая их из курсора DB порциями,
+// чтобы не загружать всю библиотеку в память. Ошибка fn прерывает выгрузку.
+func (r *songRepository) ExportSongs(
+	ctx context.Context,
+	filter entities.SongFilter,
+	sort entities.SongSort,
+	withText bool,
+	fn func(song entities.Song) error,
+) error {
+	const op = "internal.repository.ExportSongs"
+
+	ctx, cancel := context.WithTimeout(ctx, r.exportTimeout)
+	defer cancel()
 
+	q, err := buildSongListQuery(filter, sort)
+	if err != nil {
+		slog.Error(op, "Ошибка формирования запроса", slog.String("error", err.Error()))
+		return err
+	}
+	q.omitText = !withText
+
+	// Курсор существует только внутри транзакции
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Error(op, "Ошибка начала транзакции", slog.String("error", err.Error()))
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, "DECLARE songs_export NO SCROLL CURSOR FOR "+q.sql("ORDER BY "+q.order()), q.args...); err != nil {
+		slog.Error(op, "Ошибка открытия курсора", slog.String("error", err.Error()))
+		return err
+	}
+
+	for {
+		n, err := r.fetchSongs(ctx, tx, fn)
+		if err != nil {
+			slog.Error(op, "Ошибка чтения курсора", slog.String("error", err.Error()))
+			return err
+		}
+		if n < exportBatchSize {
+			break
+		}
+	}
+	return tx.Commit()
+}
+
+// fetchSongs читает очередную порцию песен из курсора выгрузки и возвращает их количество
+func (r *songRepository) fetchSongs(ctx context.Context, tx *sql.Tx, fn func(song entities.Song) error) (int, error) {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf("FETCH %d FROM songs_export", exportBatchSize))
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	n := 0
+	for rows.Next() {
+		song, err := scanSong(rows)
+		if err != nil {
+			return n, err
+		}
+		n++
+		if err = fn(song); err != nil {
+			return n, err
+		}
+	}
+	return n, rows.Err()
+}
+
+// querySongs выполняет запрос списка песен и сканирует результат
+func (r *songRepository) querySongs(ctx context.Context, op, query string, args ...interface{}) ([]entities.Song, error) {
 	// Делаем запрос к DB
-	rows, err := r.db.Query(query, args...)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		slog.Error(op, "Ошибка запроса к DB", slog.String("error", err.Error()))
 		return nil, err
 	}
+	defer rows.Close()
 
 	songs := make([]entities.Song, 0)
 	for rows.Next() {
-		var song entities.Song
-		if err = rows.Scan(
-			&song.ID,
-			&song.Group,
-			&song.Title,
-			&song.ReleaseDate,
-			&song.Text,
-			&song.Link,
-		); err != nil {
+		song, err := scanSong(rows)
+		if err != nil {
 			slog.Error(op, "Ошибка сканирования результата", slog.String("error", err.Error()))
 			return nil, err
 		}
 		songs = append(songs, song)
 	}
+	if err = rows.Err(); err != nil {
+		slog.Error(op, "Ошибка чтения результата", slog.String("error", err.Error()))
+		return nil, err
+	}
 	return songs, nil
 }
 
-func (r *songRepository) DeleteSong(id int) error {
+// scanSong читает песню из строки результата запроса списка песен
+func scanSong(rows *sql.Rows) (entities.Song, error) {
+	var (
+		song      entities.Song
+		score     sql.NullFloat64
+		deletedAt sql.NullTime
+	)
+	if err := rows.Scan(
+		&song.ID,
+		&song.ArtistID,
+		&song.Group,
+		&song.Title,
+		&song.ReleaseDate,
+		&song.ReleaseDatePrecision,
+		&song.Text,
+		&song.Link,
+		&song.Version,
+		&song.EnrichmentStatus,
+		&score,
+		&deletedAt,
+	); err != nil {
+		return song, err
+	}
+	if score.Valid {
+		song.Similarity = &score.Float64
+	}
+	if deletedAt.Valid {
+		song.DeletedAt = &deletedAt.Time
+	}
+	return song, nil
+}
+
+func (r *songRepository) DeleteSong(ctx context.Context, id, version int) error {
 	const op = "internal.repository.DeleteSong"
 
-	query := `DELETE FROM songs WHERE id = $1`
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	// Песня не удаляется, а перемещается в корзину
+	query := `UPDATE songs SET deleted_at = now(), version = version + 1
+			  WHERE id = $1 AND deleted_at IS NULL AND ($2 = 0 OR version = $2)`
 
-	if _, err := r.db.Exec(query, id); err != nil {
+	res, err := r.db.ExecContext(ctx, query, id, version)
+	if err != nil {
 		slog.Error(op, "Ошибка при удалении записи с DB", slog.String("error", err.Error()))
 		return err
 	}
-	return nil
+	return r.checkVersion(ctx, op, res, id, version)
+}
+
+// checkVersion разбирает, почему запись с проверкой версии не изменила ни одной строки:
+// песни нет (entities.ErrNotFound) или её версия изменилась (entities.ErrVersionConflict).
+func (r *songRepository) checkVersion(ctx context.Context, op string, res sql.Result, id, version int) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		slog.Error(op, "Ошибка получения количества строк", slog.String("error", err.Error()))
+		return err
+	}
+	if affected > 0 {
+		return nil
+	}
+	if version == 0 {
+		return songNotFound(id)
+	}
+
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM songs WHERE id = $1 AND deleted_at IS NULL)`
+	if err = r.db.QueryRowContext(ctx, query, id).Scan(&exists); err != nil {
+		slog.Error(op, "Ошибка запроса к DB", slog.String("error", err.Error()))
+		return err
+	}
+	if !exists {
+		return songNotFound(id)
+	}
+	return entities.ErrVersionConflict
+}
+
+// songNotFound возвращает ошибку entities.ErrNotFound для песни id
+func songNotFound(id int) error {
+	return fmt.Errorf("песня %d: %w", id, entities.ErrNotFound)
+}
+
+func (r *songRepository) ListTrash(ctx context.Context, limit, offset int) ([]entities.Song, error) {
+	const op = "internal.repository.ListTrash"
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	query := `SELECT s.id, s.artist_id, a.name, s.song_title, ` + releaseDateSelect + `, coalesce(s.release_date_precision, ''),
+				  s.text, s.link, s.version, s.enrichment_status, NULL::float8 AS score, s.deleted_at
+			  FROM songs s JOIN artists a ON a.id = s.artist_id WHERE s.deleted_at IS NOT NULL
+			  ORDER BY s.deleted_at DESC, s.id LIMIT $1 OFFSET $2`
+
+	return r.querySongs(ctx, op, query, limit, offset)
+}
+
+func (r *songRepository) RestoreSong(ctx context.Context, id int) error {
+	const op = "internal.repository.RestoreSong"
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	query := `UPDATE songs SET deleted_at = NULL, version = version + 1 WHERE id = $1 AND deleted_at IS NOT NULL`
+
+	res, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		if isDuplicateSong(err) {
+			return entities.ErrDuplicateSong
+		}
+		slog.Error(op, "Ошибка при восстановлении записи", slog.String("error", err.Error()))
+		return err
+	}
+	return checkAffected(op, res, fmt.Errorf("песня %d в корзине: %w", id, entities.ErrNotFound))
 }
 
-func (r *songRepository) UpdateSong(song entities.Song) error {
+func (r *songRepository) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	const op = "internal.repository.PurgeTrash"
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	query := `DELETE FROM songs WHERE deleted_at IS NOT NULL AND deleted_at < $1`
+
+	res, err := r.db.ExecContext(ctx, query, before)
+	if err != nil {
+		slog.Error(op, "Ошибка при очистке корзины", slog.String("error", err.Error()))
+		return 0, err
+	}
+	purged, err := res.RowsAffected()
+	if err != nil {
+		slog.Error(op, "Ошибка получения количества строк", slog.String("error", err.Error()))
+		return 0, err
+	}
+	return purged, nil
+}
+
+// releaseDateArgs переводит дату выпуска в параметры столбцов release_date и release_date_precision.
+// Пустая строка записывается как NULL.
+func releaseDateArgs(value string) (interface{}, interface{}, error) {
+	if value == "" {
+		return nil, nil, nil
+	}
+	date, err := entities.ParseReleaseDate(value)
+	if err != nil {
+		return nil, nil, err
+	}
+	return date.Date, string(date.Precision), nil
+}
+
+func (r *songRepository) UpdateSong(ctx context.Context, song entities.Song) error {
 	const op = "internal.repository.UpdateSong"
 
-	query := `UPDATE songs SET group_name=$1, song_title=$2, release_date=$3, text=$4, link=$5 WHERE id=$6`
-	_, err := r.db.Exec(query, song.Group, song.Title, song.ReleaseDate, song.Text, song.Link, song.ID)
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	releaseDate, precision, err := releaseDateArgs(song.ReleaseDate)
 	if err != nil {
+		slog.Error(op, "Ошибка разбора даты выпуска", slog.String("error", err.Error()))
+		return err
+	}
+
+	// Исполнитель создаётся, если его ещё нет в таблице artists
+	query := `WITH artist AS (
+				  INSERT INTO artists (name) VALUES ($1)
+				  ON CONFLICT ((lower(btrim(name)))) DO UPDATE SET name = artists.name
+				  RETURNING id
+			  )
+			  UPDATE songs SET artist_id=(SELECT id FROM artist), song_title=$2, release_date=$3,
+				  release_date_precision=$4, text=$5, link=$6, version=version+1
+			  WHERE id=$7 AND deleted_at IS NULL AND ($8 = 0 OR version = $8)`
+	res, err := r.db.ExecContext(ctx, query,
+		song.Group,
+		song.Title,
+		releaseDate,
+		precision,
+		song.Text,
+		song.Link,
+		song.ID,
+		song.Version)
+	if err != nil {
+		if isDuplicateSong(err) {
+			return entities.ErrDuplicateSong
+		}
 		slog.Error(op, "Ошибка при изменении данных в DB", slog.String("error", err.Error()))
 		return err
 	}
-	return nil
+	return r.checkVersion(ctx, op, res, song.ID, song.Version)
 }
 
-func (r *songRepository) CreateSong(song entities.Song) (int, error) {
+func (r *songRepository) PatchSong(ctx context.Context, id int, patch entities.SongPatch, version int) error {
+	const op = "internal.repository.PatchSong"
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if patch.IsEmpty() {
+		return nil
+	}
+
+	// Формируем список SET только из переданных полей
+	with := ""
+	set := make([]string, 0)
+	args := make([]interface{}, 0)
+	if patch.Group != nil {
+		args = append(args, *patch.Group)
+		with = fmt.Sprintf(`WITH artist AS (
+				  INSERT INTO artists (name) VALUES ($%d)
+				  ON CONFLICT ((lower(btrim(name)))) DO UPDATE SET name = artists.name
+				  RETURNING id
+			  ) `, len(args))
+		set = append(set, "artist_id=(SELECT id FROM artist)")
+	}
+	if patch.ReleaseDate != nil {
+		releaseDate, precision, err := releaseDateArgs(*patch.ReleaseDate)
+		if err != nil {
+			slog.Error(op, "Ошибка разбора даты выпуска", slog.String("error", err.Error()))
+			return err
+		}
+		args = append(args, releaseDate, precision)
+		set = append(set, fmt.Sprintf("release_date=$%d, release_date_precision=$%d", len(args)-1, len(args)))
+	}
+	for _, field := range []struct {
+		column string
+		value  *string
+	}{
+		{"song_title", patch.Title},
+		{"text", patch.Text},
+		{"link", patch.Link},
+	} {
+		if field.value == nil {
+			continue
+		}
+		args = append(args, *field.value)
+		set = append(set, fmt.Sprintf("%s=$%d", field.column, len(args)))
+	}
+	set = append(set, "version=version+1")
+
+	args = append(args, id, version)
+	query := fmt.Sprintf(`%sUPDATE songs SET %s
+			  WHERE id=$%d AND deleted_at IS NULL AND ($%d = 0 OR version = $%d)`,
+		with, strings.Join(set, ", "), len(args)-1, len(args), len(args))
+
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		if isDuplicateSong(err) {
+			return entities.ErrDuplicateSong
+		}
+		slog.Error(op, "Ошибка при изменении данных в DB", slog.String("error", err.Error()))
+		return err
+	}
+	return r.checkVersion(ctx, op, res, id, version)
+}
+
+func (r *songRepository) CreateSong(ctx context.Context, song entities.Song) (int, error) {
 	const op = "internal.repository.CreateSong"
 
-	query := `INSERT INTO songs (group_name, song_title, release_date, text, link)
-			  VALUES ($1, $2, $3, $4, $5) RETURNING id`
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	releaseDate, precision, err := releaseDateArgs(song.ReleaseDate)
+	if err != nil {
+		slog.Error(op, "Ошибка разбора даты выпуска", slog.String("error", err.Error()))
+		return 0, err
+	}
+
+	status := song.EnrichmentStatus
+	if status == "" {
+		status = entities.EnrichmentDone
+	}
+
+	// Исполнитель создаётся, если его ещё нет в таблице artists.
+	// Задача обогащения ставится в очередь в том же запросе, что и песня
+	query := `WITH artist AS (
+				  INSERT INTO artists (name) VALUES ($1)
+				  ON CONFLICT ((lower(btrim(name)))) DO UPDATE SET name = artists.name
+				  RETURNING id
+			  ), song AS (
+				  INSERT INTO songs (artist_id, song_title, release_date, release_date_precision, text, link, enrichment_status)
+				  SELECT id, $2, $3, $4, $5, $6, $7 FROM artist RETURNING id, enrichment_status
+			  ), job AS (
+				  INSERT INTO enrichment_jobs (song_id) SELECT id FROM song WHERE enrichment_status = 'pending'
+			  )
+			  SELECT id FROM song`
 
 	var id int
-	if err := r.db.QueryRow(query,
+	if err = r.db.QueryRowContext(ctx, query,
 		song.Group,
 		song.Title,
-		song.ReleaseDate,
+		releaseDate,
+		precision,
 		song.Text,
-		song.Link).Scan(&id); err != nil {
+		song.Link,
+		status).Scan(&id); err != nil {
+		if isDuplicateSong(err) {
+			return 0, entities.ErrDuplicateSong
+		}
 		slog.Error(op, "Ошибка изменения данных", slog.String("error", err.Error()))
-		return 0, nil
+		return 0, err
 	}
 	return id, nil
 }
 
-func (r *songRepository) GetSongByID(id int) (*entities.Song, error) {
+// EnqueueEnrichment ставит песню в очередь обогащения. Если у песни уже есть незавершённая задача,
+// новая не создаётся, а overwrite добавляется к существующей.
+func (r *songRepository) EnqueueEnrichment(ctx context.Context, id int, overwrite bool) error {
+	const op = "internal.repository.EnqueueEnrichment"
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	query := `WITH song AS (
+				  UPDATE songs SET enrichment_status = 'pending' WHERE id = $1 AND deleted_at IS NULL RETURNING id
+			  )
+			  INSERT INTO enrichment_jobs (song_id, overwrite) SELECT id, $2 FROM song
+			  ON CONFLICT (song_id) WHERE status IN ('pending', 'running')
+			  DO UPDATE SET overwrite = enrichment_jobs.overwrite OR EXCLUDED.overwrite, updated_at = now()`
+
+	res, err := r.db.ExecContext(ctx, query, id, overwrite)
+	if err != nil {
+		slog.Error(op, "Ошибка постановки задачи обогащения", slog.String("error", err.Error()))
+		return err
+	}
+	return checkAffected(op, res, songNotFound(id))
+}
+
+func (r *songRepository) GetSongByID(ctx context.Context, id int) (*entities.Song, error) {
 	const op = "internal.repository.GetSongByID"
 
-	query := `SELECT * FROM WHERE id=$1`
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	query := `SELECT s.id, s.artist_id, a.name, s.song_title, ` + releaseDateSelect + `, coalesce(s.release_date_precision, ''),
+				  s.text, s.link, s.version, s.enrichment_status
+			  FROM songs s JOIN artists a ON a.id = s.artist_id WHERE s.id=$1 AND s.deleted_at IS NULL`
 
-	row := r.db.QueryRow(query, id)
+	row := r.db.QueryRowContext(ctx, query, id)
 
 	var song entities.Song
 	if err := row.Scan(&song.ID,
+		&song.ArtistID,
 		&song.Group,
 		&song.Title,
 		&song.ReleaseDate,
+		&song.ReleaseDatePrecision,
 		&song.Text,
 		&song.Link,
+		&song.Version,
+		&song.EnrichmentStatus,
 	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, songNotFound(id)
+		}
 		slog.Error(op, "Ошибка парсинга данных", slog.String("error", err.Error()))
 		return nil, err
 	}
 	return &song, nil
 }
+
+// CountRevisions возвращает количество ревизий песни
+func (r *songRepository) CountRevisions(ctx context.Context, id int) (int, error) {
+	const op = "internal.repository.CountRevisions"
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	var total int
+	query := `SELECT count(*) FROM song_revisions WHERE song_id = $1`
+	if err := r.db.QueryRowContext(ctx, query, id).Scan(&total); err != nil {
+		slog.Error(op, "Ошибка запроса к DB", slog.String("error", err.Error()))
+		return 0, err
+	}
+	return total, nil
+}
+
+// FindSong ищет песню по группе и названию без учёта регистра и пробелов по краям,
+// так же, как их сравнивает уникальный индекс песен
+func (r *songRepository) FindSong(ctx context.Context, group, title string) (*entities.Song, error) {
+	const op = "internal.repository.FindSong"
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	query := `SELECT s.id, s.artist_id, a.name, s.song_title, s.version
+			  FROM songs s JOIN artists a ON a.id = s.artist_id
+			  WHERE lower(btrim(a.name)) = lower(btrim($1)) AND lower(btrim(s.song_title)) = lower(btrim($2))
+				  AND s.deleted_at IS NULL
+			  ORDER BY s.id LIMIT 1`
+
+	var song entities.Song
+	if err := r.db.QueryRowContext(ctx, query, group, title).Scan(
+		&song.ID,
+		&song.ArtistID,
+		&song.Group,
+		&song.Title,
+		&song.Version,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("песня %q группы %q: %w", title, group, entities.ErrNotFound)
+		}
+		slog.Error(op, "Ошибка запроса к DB", slog.String("error", err.Error()))
+		return nil, err
+	}
+	return &song, nil
+}
+
+// ListDuplicates ищет пары песен, у которых и группы, и названия похожи не меньше threshold.
+// Сходство пары считается по триграммам как среднее сходства групп и названий.
+func (r *songRepository) ListDuplicates(ctx context.Context, threshold float64, limit, offset int) ([]entities.SongDuplicate, error) {
+	const op = "internal.repository.ListDuplicates"
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	// Оператор % сравнивает с pg_trgm.similarity_threshold (по умолчанию 0.3), поэтому порог
+	// задаётся на время транзакции: так кандидаты отбираются по индексам триграмм с порогом запроса
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		slog.Error(op, "Ошибка начала транзакции", slog.String("error", err.Error()))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `SELECT set_config('pg_trgm.similarity_threshold', $1, true)`
+	if _, err = tx.ExecContext(ctx, query, strconv.FormatFloat(threshold, 'f', -1, 64)); err != nil {
+		slog.Error(op, "Ошибка установки порога сходства", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	// Порогу должны соответствовать и группы, и названия: одинаковые названия
+	// разных исполнителей (каверы, "Intro") дубликатами не считаются
+	query = `SELECT s.id, s.artist_id, a.name, s.song_title, d.id, d.artist_id, da.name, d.song_title,
+				  (p.group_score + p.title_score) / 2
+			  FROM songs s
+			  JOIN artists a ON a.id = s.artist_id
+			  JOIN songs d ON d.id > s.id AND d.deleted_at IS NULL AND d.song_title % s.song_title
+			  JOIN artists da ON da.id = d.artist_id AND da.name % a.name
+			  CROSS JOIN LATERAL (
+				  SELECT similarity(a.name, da.name) AS group_score, similarity(s.song_title, d.song_title) AS title_score
+			  ) p
+			  WHERE s.deleted_at IS NULL AND p.group_score >= $1 AND p.title_score >= $1
+			  ORDER BY (p.group_score + p.title_score) / 2 DESC, s.id, d.id
+			  LIMIT $2 OFFSET $3`
+
+	rows, err := tx.QueryContext(ctx, query, threshold, limit, offset)
+	if err != nil {
+		slog.Error(op, "Ошибка запроса к DB", slog.String("error", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	duplicates := make([]entities.SongDuplicate, 0)
+	for rows.Next() {
+		var duplicate entities.SongDuplicate
+		if err = rows.Scan(
+			&duplicate.Song.ID,
+			&duplicate.Song.ArtistID,
+			&duplicate.Song.Group,
+			&duplicate.Song.Title,
+			&duplicate.Duplicate.ID,
+			&duplicate.Duplicate.ArtistID,
+			&duplicate.Duplicate.Group,
+			&duplicate.Duplicate.Title,
+			&duplicate.Similarity,
+		); err != nil {
+			slog.Error(op, "Ошибка сканирования результата", slog.String("error", err.Error()))
+			return nil, err
+		}
+		duplicates = append(duplicates, duplicate)
+	}
+	if err = rows.Err(); err != nil {
+		slog.Error(op, "Ошибка чтения результата", slog.String("error", err.Error()))
+		return nil, err
+	}
+	return duplicates, nil
+}
+
+// MergeSongs дополняет пустые поля песни targetID данными песни sourceID
+// и перемещает sourceID в корзину. Обе операции выполняются в одной транзакции.
+func (r *songRepository) MergeSongs(ctx context.Context, targetID, sourceID, version int) error {
+	const op = "internal.repository.MergeSongs"
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Error(op, "Ошибка начала транзакции", slog.String("error", err.Error()))
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE songs t SET
+				  release_date = coalesce(t.release_date, m.release_date),
+				  release_date_precision = CASE WHEN t.release_date IS NULL
+					  THEN m.release_date_precision ELSE t.release_date_precision END,
+				  text = CASE WHEN coalesce(t.text, '') = '' THEN m.text ELSE t.text END,
+				  link = CASE WHEN coalesce(t.link, '') = '' THEN m.link ELSE t.link END,
+				  version = t.version + 1
+			  FROM songs m
+			  WHERE t.id = $1 AND t.deleted_at IS NULL AND ($3 = 0 OR t.version = $3)
+				  AND m.id = $2 AND m.deleted_at IS NULL`
+	res, err := tx.ExecContext(ctx, query, targetID, sourceID, version)
+	if err != nil {
+		slog.Error(op, "Ошибка при изменении данных в DB", slog.String("error", err.Error()))
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		slog.Error(op, "Ошибка получения количества строк", slog.String("error", err.Error()))
+		return err
+	}
+	if affected == 0 {
+		// Сначала проверяем, что обе песни существуют, и только потом версию
+		var exists bool
+		query = `SELECT count(*) = 2 FROM songs WHERE id IN ($1, $2) AND deleted_at IS NULL`
+		if err = tx.QueryRowContext(ctx, query, targetID, sourceID).Scan(&exists); err != nil {
+			slog.Error(op, "Ошибка запроса к DB", slog.String("error", err.Error()))
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("песня %d или %d: %w", targetID, sourceID, entities.ErrNotFound)
+		}
+		return entities.ErrVersionConflict
+	}
+
+	query = `UPDATE songs SET deleted_at = now(), version = version + 1 WHERE id = $1`
+	if _, err = tx.ExecContext(ctx, query, sourceID); err != nil {
+		slog.Error(op, "Ошибка при удалении записи с DB", slog.String("error", err.Error()))
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *songRepository) SearchSongs(ctx context.Context, q string, limit, offset int) ([]entities.SongSearchResult, error) {
+	const op = "internal.repository.SearchSongs"
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	// Номера куплетов считаются так же, как в пагинации текста: одна строка - один куплет
+	query := `SELECT s.id, s.artist_id, a.name, s.song_title,
+				  ts_rank(s.search_vector, q) AS rank,
+				  ts_headline('simple', coalesce(s.text, ''), q,
+					  'StartSel=<b>, StopSel=</b>, MaxFragments=3, FragmentDelimiter=" ... "') AS snippet,
+				  coalesce((SELECT array_agg(v.n ORDER BY v.n)
+							FROM unnest(string_to_array(s.text, E'\n')) WITH ORDINALITY AS v(line, n)
+							WHERE to_tsvector('simple', v.line) @@ q), '{}') AS verses
+			  FROM songs s
+			  JOIN artists a ON a.id = s.artist_id,
+			  websearch_to_tsquery('simple', $1) q
+			  WHERE s.search_vector @@ q AND s.deleted_at IS NULL
+			  ORDER BY rank DESC, s.id
+			  LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.QueryContext(ctx, query, q, limit, offset)
+	if err != nil {
+		slog.Error(op, "Ошибка запроса к DB", slog.String("error", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := make([]entities.SongSearchResult, 0)
+	for rows.Next() {
+		var (
+			result entities.SongSearchResult
+			verses pq.Int64Array
+		)
+		if err = rows.Scan(
+			&result.ID,
+			&result.ArtistID,
+			&result.Group,
+			&result.Title,
+			&result.Rank,
+			&result.Snippet,
+			&verses,
+		); err != nil {
+			slog.Error(op, "Ошибка сканирования результата", slog.String("error", err.Error()))
+			return nil, err
+		}
+		result.Verses = make([]int, 0, len(verses))
+		for _, n := range verses {
+			result.Verses = append(result.Verses, int(n))
+		}
+		results = append(results, result)
+	}
+	if err = rows.Err(); err != nil {
+		slog.Error(op, "Ошибка чтения результата", slog.String("error", err.Error()))
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,118 @@
+package songinfo
+
+import (
+	"TestEffectiveMobile/internal/entities"
+	"sync"
+	"time"
+)
+
+// CircuitBreaker автоматический выключатель: после failureThreshold неудач подряд вызовы внешнего API
+// на openTimeout завершаются сразу, затем пропускается один пробный запрос.
+type CircuitBreaker struct {
+	mu               sync.Mutex
+	failureThreshold int
+	openTimeout      time.Duration
+
+	state     entities.BreakerState
+	failures  int
+	openedAt  time.Time
+	probing   bool
+	lastError string
+
+	requests      int64
+	totalFailures int64
+	rejected      int64
+}
+
+// NewCircuitBreaker создаёт закрытый выключатель
+func NewCircuitBreaker(failureThreshold int, openTimeout time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		failureThreshold: max(failureThreshold, 1),
+		openTimeout:      openTimeout,
+		state:            entities.BreakerClosed,
+	}
+}
+
+// allow сообщает, можно ли выполнить запрос. В половинчатом состоянии пропускается только один запрос.
+func (b *CircuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == entities.BreakerOpen && time.Since(b.openedAt) >= b.openTimeout {
+		b.state = entities.BreakerHalfOpen
+		b.probing = false
+	}
+
+	switch b.state {
+	case entities.BreakerOpen:
+		b.rejected++
+		return false
+	case entities.BreakerHalfOpen:
+		if b.probing {
+			b.rejected++
+			return false
+		}
+		b.probing = true
+	}
+	b.requests++
+	return true
+}
+
+// success записывает удачный запрос и закрывает выключатель
+func (b *CircuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = entities.BreakerClosed
+	b.failures = 0
+	b.probing = false
+}
+
+// failure записывает неудачный запрос. Выключатель открывается после failureThreshold неудач подряд
+// или сразу, если не удался пробный запрос.
+func (b *CircuitBreaker) failure(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.totalFailures++
+	b.lastError = err.Error()
+	if b.state == entities.BreakerHalfOpen || b.failures >= b.failureThreshold {
+		b.state = entities.BreakerOpen
+		b.openedAt = time.Now()
+		b.probing = false
+	}
+}
+
+// cancel освобождает пробный запрос, прерванный вызывающей стороной: его результат ничего не говорит о внешнем API
+func (b *CircuitBreaker) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+// Status возвращает снимок состояния выключателя
+func (b *CircuitBreaker) Status() entities.UpstreamStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := entities.UpstreamStatus{
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+		FailureThreshold:    b.failureThreshold,
+		LastError:           b.lastError,
+		Requests:            b.requests,
+		Failures:            b.totalFailures,
+		Rejected:            b.rejected,
+	}
+	if !b.openedAt.IsZero() {
+		openedAt := b.openedAt
+		status.OpenedAt = &openedAt
+	}
+	if b.state == entities.BreakerOpen {
+		retryAt := b.openedAt.Add(b.openTimeout)
+		status.RetryAt = &retryAt
+	}
+	return status
+}
@@ -0,0 +1,51 @@
+package songinfo
+
+import (
+	"TestEffectiveMobile/internal/entities"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	errUpstream := errors.New("503")
+	b := NewCircuitBreaker(2, time.Minute)
+
+	// expire переносит открытие выключателя в прошлое, чтобы не ждать openTimeout
+	expire := func() {
+		b.mu.Lock()
+		b.openedAt = time.Now().Add(-time.Hour)
+		b.mu.Unlock()
+	}
+	steps := []struct {
+		name      string
+		do        func()
+		wantAllow bool
+		wantState entities.BreakerState
+	}{
+		{name: "закрытый пропускает", do: func() {}, wantAllow: true, wantState: entities.BreakerClosed},
+		{name: "одна неудача не открывает", do: func() { b.failure(errUpstream) }, wantAllow: true, wantState: entities.BreakerClosed},
+		{name: "успех сбрасывает счётчик", do: func() { b.success(); b.failure(errUpstream) }, wantAllow: true, wantState: entities.BreakerClosed},
+		{name: "порог неудач подряд открывает", do: func() { b.failure(errUpstream) }, wantAllow: false, wantState: entities.BreakerOpen},
+		{name: "после openTimeout пропускается пробный запрос", do: expire, wantAllow: true, wantState: entities.BreakerHalfOpen},
+		{name: "второй запрос во время пробы отклоняется", do: func() {}, wantAllow: false, wantState: entities.BreakerHalfOpen},
+		{name: "прерванная проба освобождает место", do: b.cancel, wantAllow: true, wantState: entities.BreakerHalfOpen},
+		{name: "неудачная проба снова открывает", do: func() { b.failure(errUpstream) }, wantAllow: false, wantState: entities.BreakerOpen},
+		{name: "удачная проба закрывает", do: func() { expire(); b.allow(); b.success() }, wantAllow: true, wantState: entities.BreakerClosed},
+	}
+
+	for _, step := range steps {
+		step.do()
+		if allowed := b.allow(); allowed != step.wantAllow {
+			t.Fatalf("%s: allow() = %v, ожидалось %v", step.name, allowed, step.wantAllow)
+		}
+		if state := b.Status().State; state != step.wantState {
+			t.Fatalf("%s: состояние %q, ожидалось %q", step.name, state, step.wantState)
+		}
+	}
+
+	status := b.Status()
+	if status.Failures != 4 || status.Rejected != 3 || status.LastError != errUpstream.Error() {
+		t.Errorf("счётчики %+v, ожидалось 4 неудачи и 3 отклонённых запроса", status)
+	}
+}
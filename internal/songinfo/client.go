@@ -0,0 +1,216 @@
+package songinfo
+
+import (
+	"TestEffectiveMobile/internal/entities"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrCircuitOpen вызов отклонён открытым выключателем без обращения к внешнему API
+var ErrCircuitOpen = fmt.Errorf("%w: внешнее API временно недоступно", entities.ErrUpstream)
+
+// errInvalidResponse внешнее API ответило 200 с телом, которое не удалось разобрать
+var errInvalidResponse = errors.New("неверный ответ")
+
+// StatusError внешнее API ответило статусом, отличным от 200
+type StatusError struct {
+	StatusCode int
+	// RetryAfter пауза из заголовка Retry-After, 0 если заголовка нет
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s: внешнее API вернуло статус %d", entities.ErrUpstream, e.StatusCode)
+}
+
+func (e *StatusError) Unwrap() error {
+	return entities.ErrUpstream
+}
+
+// Is сообщает, что ответ 404 означает entities.ErrSongInfoNotFound
+func (e *StatusError) Is(target error) bool {
+	return target == entities.ErrSongInfoNotFound && e.StatusCode == http.StatusNotFound
+}
+
+// RetryPolicy правила повтора запросов к внешнему API
+type RetryPolicy struct {
+	// MaxRetries количество повторов после первой попытки
+	MaxRetries int
+	// BaseDelay пауза перед первым повтором, каждая следующая вдвое больше
+	BaseDelay time.Duration
+	// MaxDelay наибольшая пауза между попытками. Если Retry-After требует ждать дольше, повторов больше нет
+	MaxDelay time.Duration
+}
+
+type Client interface {
+	GetSongInfo(ctx context.Context, group, song string) (*entities.ExternalSongInfo, error)
+	Status() entities.UpstreamStatus
+}
+
+type client struct {
+	url        string
+	httpClient *http.Client
+	retry      RetryPolicy
+	breaker    *CircuitBreaker
+}
+
+// NewClient создаёт клиент внешнего API. timeout ограничивает время одной попытки,
+// сетевые ошибки и ответы 5xx и 429 повторяются по retry, пока их пропускает breaker.
+func NewClient(url string, timeout time.Duration, retry RetryPolicy, breaker *CircuitBreaker) Client {
+	return &client{
+		url:        url,
+		httpClient: &http.Client{Timeout: timeout},
+		retry:      retry,
+		breaker:    breaker,
+	}
+}
+
+// GetSongInfo запрашивает данные песни, повторяя временные ошибки с экспоненциальной паузой и разбросом
+func (c *client) GetSongInfo(ctx context.Context, group, song string) (*entities.ExternalSongInfo, error) {
+	const op = "internal.songinfo.GetSongInfo"
+
+	params := url.Values{}
+	params.Add("group", group)
+	params.Add("song", song)
+	fullURL := fmt.Sprintf("%s?%s", c.url, params.Encode())
+
+	var lastErr error
+	for attempt := 0; ; attempt++ {
+		if !c.breaker.allow() {
+			if lastErr != nil {
+				return nil, fmt.Errorf("%w (последняя ошибка: %v)", ErrCircuitOpen, lastErr)
+			}
+			return nil, ErrCircuitOpen
+		}
+
+		info, err := c.fetch(ctx, fullURL)
+		switch {
+		case err == nil:
+			c.breaker.success()
+			return info, nil
+		case ctx.Err() != nil:
+			c.breaker.cancel()
+			return nil, fmt.Errorf("%w: %w", entities.ErrUpstream, ctx.Err())
+		case errors.Is(err, errInvalidResponse):
+			// Неверное тело ответа 200 говорит о неисправности внешнего API, но повтор его не исправит
+			c.breaker.failure(err)
+			return nil, err
+		case !retryable(err):
+			// Ответ 4xx означает, что внешнее API работает, но не может выдать данные этой песни
+			c.breaker.success()
+			return nil, err
+		}
+
+		c.breaker.failure(err)
+		lastErr = err
+		delay, ok := c.backoff(attempt, err)
+		if !ok {
+			return nil, err
+		}
+		slog.Error(op, "Ошибка запроса обогащения, запрос будет повторён",
+			slog.Int("attempt", attempt+1), slog.Duration("delay", delay), slog.String("error", err.Error()))
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("%w: %w", entities.ErrUpstream, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// Status возвращает состояние выключателя внешнего API
+func (c *client) Status() entities.UpstreamStatus {
+	return c.breaker.Status()
+}
+
+// fetch выполняет одну попытку запроса
+func (c *client) fetch(ctx context.Context, fullURL string) (*entities.ExternalSongInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fullURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", entities.ErrUpstream, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		// Тело дочитывается, чтобы соединение вернулось в пул
+		io.Copy(io.Discard, resp.Body)
+		return nil, &StatusError{StatusCode: resp.StatusCode, RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"))}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", entities.ErrUpstream, err)
+	}
+
+	var info entities.ExternalSongInfo
+	if err = json.Unmarshal(body, &info); err != nil {
+		return nil, fmt.Errorf("%w: %w: %w", entities.ErrUpstream, errInvalidResponse, err)
+	}
+	return &info, nil
+}
+
+// retryable сообщает, что ошибку стоит повторить: сетевые ошибки, 5xx и 429
+func retryable(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= http.StatusInternalServerError || statusErr.StatusCode == http.StatusTooManyRequests
+	}
+	// Неверный JSON в ответе 200 повтор не исправит
+	return !errors.Is(err, errInvalidResponse)
+}
+
+// backoff возвращает паузу перед повтором attempt+1: BaseDelay*2^attempt со случайным разбросом
+// в половину паузы, но не меньше Retry-After. false означает, что повторов больше не будет.
+func (c *client) backoff(attempt int, err error) (time.Duration, bool) {
+	if attempt >= c.retry.MaxRetries {
+		return 0, false
+	}
+
+	delay := c.retry.BaseDelay << attempt
+	if delay <= 0 || delay > c.retry.MaxDelay {
+		delay = c.retry.MaxDelay
+	}
+	if half := int64(delay / 2); half > 0 {
+		delay = time.Duration(half + rand.Int63n(half+1))
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.RetryAfter > delay {
+		if statusErr.RetryAfter > c.retry.MaxDelay {
+			return 0, false
+		}
+		delay = statusErr.RetryAfter
+	}
+	return delay, true
+}
+
+// parseRetryAfter разбирает Retry-After в секундах или в виде даты HTTP
+func parseRetryAfter(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0)
+	}
+	return 0
+}
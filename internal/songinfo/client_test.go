@@ -0,0 +1,155 @@
+package songinfo
+
+import (
+	"TestEffectiveMobile/internal/entities"
+	"TestEffectiveMobile/internal/mockinfo"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	c := &client{retry: RetryPolicy{MaxRetries: 3, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}}
+	errUnavailable := &StatusError{StatusCode: http.StatusServiceUnavailable}
+
+	tests := []struct {
+		name    string
+		attempt int
+		err     error
+		wantMin time.Duration
+		wantMax time.Duration
+		wantOK  bool
+	}{
+		{name: "первый повтор", attempt: 0, err: errUnavailable, wantMin: 50 * time.Millisecond, wantMax: 100 * time.Millisecond, wantOK: true},
+		{name: "пауза удваивается", attempt: 2, err: errUnavailable, wantMin: 200 * time.Millisecond, wantMax: 400 * time.Millisecond, wantOK: true},
+		{name: "повторы закончились", attempt: 3, err: errUnavailable},
+		{
+			name:    "Retry-After длиннее паузы",
+			attempt: 0,
+			err:     &StatusError{StatusCode: http.StatusTooManyRequests, RetryAfter: 700 * time.Millisecond},
+			wantMin: 700 * time.Millisecond,
+			wantMax: 700 * time.Millisecond,
+			wantOK:  true,
+		},
+		{name: "Retry-After длиннее MaxDelay", attempt: 0, err: &StatusError{StatusCode: http.StatusTooManyRequests, RetryAfter: time.Minute}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Разброс случайный, поэтому проверка повторяется
+			for i := 0; i < 100; i++ {
+				delay, ok := c.backoff(tt.attempt, tt.err)
+				if ok != tt.wantOK {
+					t.Fatalf("backoff(%d) ok = %v, ожидалось %v", tt.attempt, ok, tt.wantOK)
+				}
+				if ok && (delay < tt.wantMin || delay > tt.wantMax) {
+					t.Fatalf("backoff(%d) = %v, ожидалось от %v до %v", tt.attempt, delay, tt.wantMin, tt.wantMax)
+				}
+			}
+		})
+	}
+
+	t.Run("пауза не больше MaxDelay, в том числе при переполнении сдвига", func(t *testing.T) {
+		c := &client{retry: RetryPolicy{MaxRetries: 100, BaseDelay: time.Second, MaxDelay: 2 * time.Second}}
+		for _, attempt := range []int{5, 40, 70} {
+			if delay, _ := c.backoff(attempt, errUnavailable); delay > 2*time.Second || delay < time.Second {
+				t.Errorf("backoff(%d) = %v, ожидалось от 1s до 2s", attempt, delay)
+			}
+		}
+	})
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantMin time.Duration
+		wantMax time.Duration
+	}{
+		{name: "нет заголовка", value: ""},
+		{name: "секунды", value: " 3 ", wantMin: 3 * time.Second, wantMax: 3 * time.Second},
+		{name: "ноль", value: "0"},
+		{name: "отрицательное число", value: "-5"},
+		{name: "дата в будущем", value: time.Now().Add(time.Minute).UTC().Format(http.TimeFormat), wantMin: 58 * time.Second, wantMax: time.Minute},
+		{name: "дата в прошлом", value: time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat)},
+		{name: "мусор", value: "soon"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRetryAfter(tt.value); got < tt.wantMin || got > tt.wantMax {
+				t.Errorf("parseRetryAfter(%q) = %v, ожидалось от %v до %v", tt.value, got, tt.wantMin, tt.wantMax)
+			}
+		})
+	}
+}
+
+func TestGetSongInfo(t *testing.T) {
+	fixture := mockinfo.Fixture{Group: "Muse", Song: "Uprising", ReleaseDate: "2009", Text: "Paranoia is in bloom", Link: "https://example.com"}
+
+	tests := []struct {
+		name         string
+		song         string
+		fault        *mockinfo.Fault
+		wantErr      error
+		wantRequests int64
+		wantFailures int
+	}{
+		{name: "ответ 200", song: "Uprising", wantRequests: 1},
+		{name: "404 не считается неисправностью", song: "Resistance", wantErr: entities.ErrSongInfoNotFound, wantRequests: 1},
+		{
+			name:         "503 повторяется",
+			song:         "Uprising",
+			fault:        &mockinfo.Fault{Status: http.StatusServiceUnavailable, Count: 2},
+			wantRequests: 3,
+		},
+		{
+			name:         "неверное тело не повторяется, но считается неудачей",
+			song:         "Uprising",
+			fault:        &mockinfo.Fault{Malformed: true},
+			wantErr:      errInvalidResponse,
+			wantRequests: 1,
+			wantFailures: 1,
+		},
+		{
+			name:         "503 после всех повторов",
+			song:         "Uprising",
+			fault:        &mockinfo.Fault{Status: http.StatusServiceUnavailable},
+			wantErr:      entities.ErrUpstream,
+			wantRequests: 3,
+			wantFailures: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := mockinfo.Start([]mockinfo.Fixture{fixture})
+			defer server.Close()
+			if tt.fault != nil {
+				server.AddFault(*tt.fault)
+			}
+
+			retry := RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}
+			c := NewClient(server.URL, time.Second, retry, NewCircuitBreaker(5, time.Minute))
+
+			info, err := c.GetSongInfo(context.Background(), "Muse", tt.song)
+			if tt.wantErr == nil && err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ошибка %v, ожидалась %v", err, tt.wantErr)
+			}
+			if err == nil && info.Text != fixture.Text {
+				t.Errorf("текст %q, ожидался %q", info.Text, fixture.Text)
+			}
+			if requests := server.Requests(); requests != tt.wantRequests {
+				t.Errorf("запросов %d, ожидалось %d", requests, tt.wantRequests)
+			}
+			if failures := c.Status().ConsecutiveFailures; failures != tt.wantFailures {
+				t.Errorf("неудач подряд %d, ожидалось %d", failures, tt.wantFailures)
+			}
+		})
+	}
+}
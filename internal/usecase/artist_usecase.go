@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"TestEffectiveMobile/internal/entities"
+	"TestEffectiveMobile/internal/repository"
+	"TestEffectiveMobile/internal/validation"
+	"context"
+	"strconv"
+)
+
+type ArtistUseCase interface {
+	ListArtists(ctx context.Context, limit, offset int) ([]entities.Artist, error)
+	GetArtistByID(ctx context.Context, id int) (*entities.Artist, error)
+	CreateArtist(ctx context.Context, artist entities.Artist) (int, error)
+	UpdateArtist(ctx context.Context, artist entities.Artist) error
+	DeleteArtist(ctx context.Context, id int) error
+	ListArtistSongs(ctx context.Context, id, limit, offset int) ([]entities.Song, error)
+}
+
+type artistUseCase struct {
+	repo     repository.ArtistRepository
+	songRepo repository.SongRepository
+}
+
+func NewArtistUseCase(repo repository.ArtistRepository, songRepo repository.SongRepository) ArtistUseCase {
+	return &artistUseCase{
+		repo:     repo,
+		songRepo: songRepo,
+	}
+}
+
+func (u *artistUseCase) ListArtists(ctx context.Context, limit, offset int) ([]entities.Artist, error) {
+	return u.repo.ListArtists(ctx, limit, offset)
+}
+
+func (u *artistUseCase) GetArtistByID(ctx context.Context, id int) (*entities.Artist, error) {
+	return u.repo.GetArtistByID(ctx, id)
+}
+
+func (u *artistUseCase) CreateArtist(ctx context.Context, artist entities.Artist) (int, error) {
+	if err := validation.Artist(artist); err != nil {
+		return 0, err
+	}
+	return u.repo.CreateArtist(ctx, artist)
+}
+
+func (u *artistUseCase) UpdateArtist(ctx context.Context, artist entities.Artist) error {
+	if err := validation.Artist(artist); err != nil {
+		return err
+	}
+	return u.repo.UpdateArtist(ctx, artist)
+}
+
+func (u *artistUseCase) DeleteArtist(ctx context.Context, id int) error {
+	return u.repo.DeleteArtist(ctx, id)
+}
+
+func (u *artistUseCase) ListArtistSongs(ctx context.Context, id, limit, offset int) ([]entities.Song, error) {
+	var filter entities.SongFilter
+	if err := filter.Add(entities.FieldArtistID, entities.OpEq, strconv.Itoa(id)); err != nil {
+		return nil, err
+	}
+	return u.songRepo.ListSongs(ctx, filter, nil, limit, offset)
+}
@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"TestEffectiveMobile/internal/entities"
+	"fmt"
+	"strings"
+)
+
+// maxDiffLines наибольшее число строк в каждом из сравниваемых текстов.
+// Матрица LCS занимает (len(a)+1)*(len(b)+1) ячеек, без предела один запрос
+// к длинным текстам может занять всю память процесса.
+const maxDiffLines = 2000
+
+// diffLines строит построчный diff двух текстов по наибольшей общей подпоследовательности.
+// Если в одном из текстов больше maxDiffLines строк, возвращает entities.ErrDiffTooLarge.
+func diffLines(oldText, newText string) ([]entities.DiffLine, error) {
+	a := splitLines(oldText)
+	b := splitLines(newText)
+	if len(a) > maxDiffLines || len(b) > maxDiffLines {
+		return nil, fmt.Errorf("%w: строк %d и %d, допустимо не больше %d",
+			entities.ErrDiffTooLarge, len(a), len(b), maxDiffLines)
+	}
+
+	// lcs[i][j] длина общей подпоследовательности суффиксов a[i:] и b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	lines := make([]entities.DiffLine, 0, max(len(a), len(b)))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, entities.DiffLine{Op: "equal", OldLine: i + 1, NewLine: j + 1, Text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, entities.DiffLine{Op: "delete", OldLine: i + 1, Text: a[i]})
+			i++
+		default:
+			lines = append(lines, entities.DiffLine{Op: "insert", NewLine: j + 1, Text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, entities.DiffLine{Op: "delete", OldLine: i + 1, Text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, entities.DiffLine{Op: "insert", NewLine: j + 1, Text: b[j]})
+	}
+	return lines, nil
+}
+
+// splitLines делит текст на строки так же, как пагинация куплетов
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
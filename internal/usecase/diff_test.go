@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"TestEffectiveMobile/internal/entities"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	equal := func(oldLine, newLine int, text string) entities.DiffLine {
+		return entities.DiffLine{Op: "equal", OldLine: oldLine, NewLine: newLine, Text: text}
+	}
+	insert := func(newLine int, text string) entities.DiffLine {
+		return entities.DiffLine{Op: "insert", NewLine: newLine, Text: text}
+	}
+	del := func(oldLine int, text string) entities.DiffLine {
+		return entities.DiffLine{Op: "delete", OldLine: oldLine, Text: text}
+	}
+
+	tests := []struct {
+		name    string
+		oldText string
+		newText string
+		want    []entities.DiffLine
+	}{
+		{
+			name: "пустые тексты",
+			want: []entities.DiffLine{},
+		},
+		{
+			name:    "одинаковые тексты",
+			oldText: "a\nb",
+			newText: "a\nb",
+			want:    []entities.DiffLine{equal(1, 1, "a"), equal(2, 2, "b")},
+		},
+		{
+			name:    "текст появился",
+			newText: "a\nb",
+			want:    []entities.DiffLine{insert(1, "a"), insert(2, "b")},
+		},
+		{
+			name:    "текст удалён",
+			oldText: "a\nb",
+			want:    []entities.DiffLine{del(1, "a"), del(2, "b")},
+		},
+		{
+			name:    "вставка в середину",
+			oldText: "a\nc",
+			newText: "a\nb\nc",
+			want:    []entities.DiffLine{equal(1, 1, "a"), insert(2, "b"), equal(2, 3, "c")},
+		},
+		{
+			name:    "замена строки",
+			oldText: "a\nb\nc",
+			newText: "a\nx\nc",
+			want:    []entities.DiffLine{equal(1, 1, "a"), del(2, "b"), insert(2, "x"), equal(3, 3, "c")},
+		},
+		{
+			name:    "пустые строки между куплетами",
+			oldText: "a\n\nb",
+			newText: "a\n\nb\n\nc",
+			want: []entities.DiffLine{
+				equal(1, 1, "a"), equal(2, 2, ""), equal(3, 3, "b"), insert(4, ""), insert(5, "c"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := diffLines(tt.oldText, tt.newText)
+			if err != nil {
+				t.Fatalf("diffLines(%q, %q) вернул ошибку: %v", tt.oldText, tt.newText, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffLines(%q, %q) = %+v, ожидалось %+v", tt.oldText, tt.newText, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffLinesLimit(t *testing.T) {
+	atLimit := strings.Repeat("a\n", maxDiffLines-1) + "a"
+	overLimit := atLimit + "\na"
+
+	tests := []struct {
+		name    string
+		oldText string
+		newText string
+		wantErr bool
+	}{
+		{name: "оба текста на пределе", oldText: atLimit, newText: atLimit},
+		{name: "старый текст длиннее предела", oldText: overLimit, newText: "a", wantErr: true},
+		{name: "новый текст длиннее предела", oldText: "a", newText: overLimit, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := diffLines(tt.oldText, tt.newText)
+			if tt.wantErr {
+				if !errors.Is(err, entities.ErrDiffTooLarge) || !errors.Is(err, entities.ErrValidation) {
+					t.Fatalf("ожидалась ошибка ErrDiffTooLarge, получено %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("неожиданная ошибка: %v", err)
+			}
+		})
+	}
+}
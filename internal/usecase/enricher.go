@@ -0,0 +1,234 @@
+package usecase
+
+import (
+	"TestEffectiveMobile/internal/entities"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// Enricher источник данных песни для обогащения
+type Enricher interface {
+	GetSongInfo(ctx context.Context, group, song string) (*entities.ExternalSongInfo, error)
+}
+
+// upstreamStatuser источник, который сообщает состояние своего выключателя
+type upstreamStatuser interface {
+	Status() entities.UpstreamStatus
+}
+
+// EnrichmentProvider именованный источник обогащения
+type EnrichmentProvider struct {
+	Name     string
+	Enricher Enricher
+}
+
+// EnrichmentMode порядок опроса источников обогащения
+type EnrichmentMode string
+
+const (
+	// EnrichmentModePriority источники опрашиваются по очереди и только когда их данные нужны
+	EnrichmentModePriority EnrichmentMode = "priority"
+	// EnrichmentModeParallel все нужные источники опрашиваются одновременно
+	EnrichmentModeParallel EnrichmentMode = "parallel"
+)
+
+// Поля песни, которые заполняют источники обогащения
+const (
+	EnrichmentFieldReleaseDate = "releaseDate"
+	EnrichmentFieldText        = "text"
+	EnrichmentFieldLink        = "link"
+)
+
+// enrichmentFields поля обогащения в порядке слияния
+var enrichmentFields = []struct {
+	name  string
+	value func(info *entities.ExternalSongInfo) *string
+}{
+	{EnrichmentFieldReleaseDate, func(info *entities.ExternalSongInfo) *string { return &info.ReleaseDate }},
+	{EnrichmentFieldText, func(info *entities.ExternalSongInfo) *string { return &info.Text }},
+	{EnrichmentFieldLink, func(info *entities.ExternalSongInfo) *string { return &info.Link }},
+}
+
+// EnricherRegistry объединяет несколько источников обогащения в один
+type EnricherRegistry interface {
+	Enricher
+	// Statuses возвращает состояние выключателей источников
+	Statuses() []entities.UpstreamStatus
+}
+
+type enricherRegistry struct {
+	providers []EnrichmentProvider
+	mode      EnrichmentMode
+	// merge индексы источников для каждого поля в порядке предпочтения
+	merge map[string][]int
+}
+
+// NewEnricherRegistry создаёт реестр источников обогащения. providers перечислены в порядке приоритета.
+// merge задаёт для поля список источников, из которых поле берётся: побеждает первое непустое значение.
+// Поля, которых нет в merge, берутся из всех источников в порядке приоритета.
+func NewEnricherRegistry(providers []EnrichmentProvider, mode EnrichmentMode, merge map[string][]string) (EnricherRegistry, error) {
+	if len(providers) == 0 {
+		return nil, errors.New("не задан ни один источник обогащения")
+	}
+	if mode != EnrichmentModePriority && mode != EnrichmentModeParallel {
+		return nil, fmt.Errorf("неизвестный режим опроса источников обогащения %q", mode)
+	}
+
+	indexes := make(map[string]int, len(providers))
+	priority := make([]int, 0, len(providers))
+	for i, provider := range providers {
+		if _, ok := indexes[provider.Name]; ok {
+			return nil, fmt.Errorf("источник обогащения %q задан дважды", provider.Name)
+		}
+		indexes[provider.Name] = i
+		priority = append(priority, i)
+	}
+
+	registry := &enricherRegistry{
+		providers: providers,
+		mode:      mode,
+		merge:     make(map[string][]int, len(enrichmentFields)),
+	}
+	for _, field := range enrichmentFields {
+		registry.merge[field.name] = priority
+	}
+	for field, names := range merge {
+		if _, ok := registry.merge[field]; !ok {
+			return nil, fmt.Errorf("неизвестное поле обогащения %q", field)
+		}
+		order := make([]int, 0, len(names))
+		for _, name := range names {
+			i, ok := indexes[name]
+			if !ok {
+				return nil, fmt.Errorf("поле %q ссылается на неизвестный источник обогащения %q", field, name)
+			}
+			order = append(order, i)
+		}
+		registry.merge[field] = order
+	}
+	return registry, nil
+}
+
+// providerResult ответ одного источника. info == nil и err == nil означает, что источник не знает песню.
+type providerResult struct {
+	info *entities.ExternalSongInfo
+	err  error
+	done bool
+}
+
+// GetSongInfo собирает данные песни из источников по правилам слияния. Ошибка возвращается,
+// только если поле осталось пустым и хотя бы один из его источников не ответил. Если ни один
+// опрошенный источник не знает песню, возвращается entities.ErrSongInfoNotFound.
+func (r *enricherRegistry) GetSongInfo(ctx context.Context, group, song string) (*entities.ExternalSongInfo, error) {
+	results := make([]providerResult, len(r.providers))
+	if r.mode == EnrichmentModeParallel {
+		r.fetchAll(ctx, group, song, results)
+	}
+	result := func(i int) providerResult {
+		if !results[i].done {
+			results[i] = r.fetch(ctx, i, group, song)
+		}
+		return results[i]
+	}
+
+	var info entities.ExternalSongInfo
+	failed := make(map[int]bool)
+	for _, field := range enrichmentFields {
+		var fieldFailed []int
+		for _, i := range r.merge[field.name] {
+			res := result(i)
+			if res.err != nil {
+				fieldFailed = append(fieldFailed, i)
+				continue
+			}
+			if res.info == nil {
+				continue
+			}
+			if value := *field.value(res.info); value != "" {
+				*field.value(&info) = value
+				fieldFailed = nil
+				break
+			}
+		}
+		for _, i := range fieldFailed {
+			failed[i] = true
+		}
+	}
+
+	if len(failed) > 0 {
+		errs := make([]error, 0, len(failed))
+		for i := range r.providers {
+			if failed[i] {
+				errs = append(errs, results[i].err)
+			}
+		}
+		return nil, errors.Join(errs...)
+	}
+
+	found := false
+	for _, res := range results {
+		found = found || res.info != nil
+	}
+	if !found {
+		return nil, entities.ErrSongInfoNotFound
+	}
+	return &info, nil
+}
+
+// fetchAll опрашивает одновременно все источники, которые участвуют в слиянии
+func (r *enricherRegistry) fetchAll(ctx context.Context, group, song string, results []providerResult) {
+	used := make([]bool, len(r.providers))
+	for _, order := range r.merge {
+		for _, i := range order {
+			used[i] = true
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := range r.providers {
+		if !used[i] {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = r.fetch(ctx, i, group, song)
+		}()
+	}
+	wg.Wait()
+}
+
+// fetch опрашивает источник i
+func (r *enricherRegistry) fetch(ctx context.Context, i int, group, song string) providerResult {
+	const op = "internal.useCase.fetchEnrichment"
+
+	provider := r.providers[i]
+	info, err := provider.Enricher.GetSongInfo(ctx, group, song)
+	if errors.Is(err, entities.ErrSongInfoNotFound) {
+		slog.Info(op+": источник не знает песню", "provider", provider.Name, "group", group, "song", song)
+		return providerResult{done: true}
+	}
+	if err != nil {
+		slog.Error(op, "Ошибка источника обогащения",
+			slog.String("provider", provider.Name), slog.String("error", err.Error()))
+		return providerResult{err: fmt.Errorf("источник %s: %w", provider.Name, err), done: true}
+	}
+	return providerResult{info: info, done: true}
+}
+
+func (r *enricherRegistry) Statuses() []entities.UpstreamStatus {
+	statuses := make([]entities.UpstreamStatus, 0, len(r.providers))
+	for _, provider := range r.providers {
+		statuser, ok := provider.Enricher.(upstreamStatuser)
+		if !ok {
+			continue
+		}
+		status := statuser.Status()
+		status.Provider = provider.Name
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
@@ -0,0 +1,175 @@
+package usecase
+
+import (
+	"TestEffectiveMobile/internal/entities"
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+// fakeEnricher источник обогащения с заранее заданным ответом
+type fakeEnricher struct {
+	info  *entities.ExternalSongInfo
+	err   error
+	calls atomic.Int32
+}
+
+func (e *fakeEnricher) GetSongInfo(ctx context.Context, group, song string) (*entities.ExternalSongInfo, error) {
+	e.calls.Add(1)
+	return e.info, e.err
+}
+
+func TestEnricherRegistryGetSongInfo(t *testing.T) {
+	errUnavailable := errors.New("503")
+	full := &entities.ExternalSongInfo{ReleaseDate: "2009", Text: "Paranoia", Link: "https://a.example.com"}
+	other := &entities.ExternalSongInfo{ReleaseDate: "2010", Text: "They will not force us", Link: "https://b.example.com"}
+	noText := &entities.ExternalSongInfo{ReleaseDate: "2009", Link: "https://a.example.com"}
+	notFound := func() *fakeEnricher { return &fakeEnricher{err: entities.ErrSongInfoNotFound} }
+
+	tests := []struct {
+		name      string
+		mode      EnrichmentMode
+		a, b      *fakeEnricher
+		merge     map[string][]string
+		want      *entities.ExternalSongInfo
+		wantErr   error
+		wantCalls [2]int32
+	}{
+		{
+			name:      "первый источник заполнил все поля",
+			a:         &fakeEnricher{info: full},
+			b:         &fakeEnricher{info: other},
+			want:      full,
+			wantCalls: [2]int32{1, 0},
+		},
+		{
+			name:      "пустое поле берётся из следующего источника",
+			a:         &fakeEnricher{info: noText},
+			b:         &fakeEnricher{info: other},
+			want:      &entities.ExternalSongInfo{ReleaseDate: "2009", Text: "They will not force us", Link: "https://a.example.com"},
+			wantCalls: [2]int32{1, 1},
+		},
+		{
+			name:      "правило слияния меняет порядок источников для поля",
+			a:         &fakeEnricher{info: full},
+			b:         &fakeEnricher{info: other},
+			merge:     map[string][]string{EnrichmentFieldText: {"b", "a"}},
+			want:      &entities.ExternalSongInfo{ReleaseDate: "2009", Text: "They will not force us", Link: "https://a.example.com"},
+			wantCalls: [2]int32{1, 1},
+		},
+		{
+			name:      "поле без источников остаётся пустым",
+			a:         &fakeEnricher{info: full},
+			b:         &fakeEnricher{info: other},
+			merge:     map[string][]string{EnrichmentFieldLink: {}},
+			want:      &entities.ExternalSongInfo{ReleaseDate: "2009", Text: "Paranoia"},
+			wantCalls: [2]int32{1, 0},
+		},
+		{
+			name:      "источник не знает песню",
+			a:         notFound(),
+			b:         &fakeEnricher{info: other},
+			want:      other,
+			wantCalls: [2]int32{1, 1},
+		},
+		{
+			name:      "песню не знает ни один источник",
+			a:         notFound(),
+			b:         notFound(),
+			wantErr:   entities.ErrSongInfoNotFound,
+			wantCalls: [2]int32{1, 1},
+		},
+		{
+			name:      "ошибка источника перекрывается данными следующего",
+			a:         &fakeEnricher{err: errUnavailable},
+			b:         &fakeEnricher{info: other},
+			want:      other,
+			wantCalls: [2]int32{1, 1},
+		},
+		{
+			name:      "ошибка источника, когда поле некому заполнить",
+			a:         &fakeEnricher{info: noText},
+			b:         &fakeEnricher{err: errUnavailable},
+			wantErr:   errUnavailable,
+			wantCalls: [2]int32{1, 1},
+		},
+		{
+			name:      "недоступный источник не опрашивается, если его данные не нужны",
+			a:         &fakeEnricher{info: full},
+			b:         &fakeEnricher{err: errUnavailable},
+			want:      full,
+			wantCalls: [2]int32{1, 0},
+		},
+		{
+			name:      "в параллельном режиме опрашиваются все источники слияния",
+			mode:      EnrichmentModeParallel,
+			a:         &fakeEnricher{info: full},
+			b:         &fakeEnricher{err: errUnavailable},
+			want:      full,
+			wantCalls: [2]int32{1, 1},
+		},
+		{
+			name:      "в параллельном режиме источник вне слияния не опрашивается",
+			mode:      EnrichmentModeParallel,
+			a:         &fakeEnricher{info: full},
+			b:         &fakeEnricher{info: other},
+			merge:     map[string][]string{EnrichmentFieldReleaseDate: {"a"}, EnrichmentFieldText: {"a"}, EnrichmentFieldLink: {"a"}},
+			want:      full,
+			wantCalls: [2]int32{1, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mode := tt.mode
+			if mode == "" {
+				mode = EnrichmentModePriority
+			}
+			registry, err := NewEnricherRegistry([]EnrichmentProvider{{Name: "a", Enricher: tt.a}, {Name: "b", Enricher: tt.b}}, mode, tt.merge)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := registry.GetSongInfo(context.Background(), "Muse", "Uprising")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ошибка %v, ожидалась %v", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			} else if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetSongInfo() = %+v, ожидалось %+v", got, tt.want)
+			}
+			if calls := [2]int32{tt.a.calls.Load(), tt.b.calls.Load()}; calls != tt.wantCalls {
+				t.Errorf("вызовов источников %v, ожидалось %v", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestNewEnricherRegistryInvalid(t *testing.T) {
+	provider := EnrichmentProvider{Name: "a", Enricher: &fakeEnricher{}}
+
+	tests := []struct {
+		name      string
+		providers []EnrichmentProvider
+		mode      EnrichmentMode
+		merge     map[string][]string
+	}{
+		{name: "нет источников", mode: EnrichmentModePriority},
+		{name: "неизвестный режим", providers: []EnrichmentProvider{provider}, mode: "random"},
+		{name: "источник задан дважды", providers: []EnrichmentProvider{provider, provider}, mode: EnrichmentModePriority},
+		{name: "неизвестное поле", providers: []EnrichmentProvider{provider}, mode: EnrichmentModePriority, merge: map[string][]string{"lyrics": {"a"}}},
+		{name: "неизвестный источник", providers: []EnrichmentProvider{provider}, mode: EnrichmentModePriority, merge: map[string][]string{EnrichmentFieldText: {"b"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewEnricherRegistry(tt.providers, tt.mode, tt.merge); err == nil {
+				t.Error("NewEnricherRegistry без ошибки")
+			}
+		})
+	}
+}
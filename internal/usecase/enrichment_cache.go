@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"TestEffectiveMobile/internal/entities"
+	"TestEffectiveMobile/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// enrichmentCall запрос к источникам, результат которого ждут все одинаковые запросы
+type enrichmentCall struct {
+	done chan struct{}
+	info *entities.ExternalSongInfo
+	err  error
+}
+
+type cachedEnricher struct {
+	enricher      EnricherRegistry
+	cache         repository.EnrichmentCacheRepository
+	ttl           time.Duration
+	notFoundTTL   time.Duration
+	lookupTimeout time.Duration
+
+	mu    sync.Mutex
+	calls map[string]*enrichmentCall
+}
+
+// NewCachedEnricher сохраняет ответы enricher в DB: найденные песни на ttl, ответ «не найдено» на notFoundTTL.
+// Одновременные запросы одной песни ждут один общий запрос к источникам, который длится не дольше lookupTimeout.
+func NewCachedEnricher(
+	enricher EnricherRegistry,
+	cache repository.EnrichmentCacheRepository,
+	ttl time.Duration,
+	notFoundTTL time.Duration,
+	lookupTimeout time.Duration,
+) EnricherRegistry {
+	return &cachedEnricher{
+		enricher:      enricher,
+		cache:         cache,
+		ttl:           ttl,
+		notFoundTTL:   notFoundTTL,
+		lookupTimeout: lookupTimeout,
+		calls:         make(map[string]*enrichmentCall),
+	}
+}
+
+// GetSongInfo возвращает сохранённый ответ, а если его нет, запрашивает источники.
+// Общий запрос к источникам не зависит от ctx вызова, который его начал: отмена одного вызова
+// не обрывает его для остальных. Каждый вызов ждёт результат или отмены своего ctx.
+func (c *cachedEnricher) GetSongInfo(ctx context.Context, group, song string) (*entities.ExternalSongInfo, error) {
+	// Ключ совпадает с ключом записи в DB: регистр и пробелы по краям не учитываются
+	key := strings.ToLower(strings.TrimSpace(group)) + "\x00" + strings.ToLower(strings.TrimSpace(song))
+
+	c.mu.Lock()
+	call, ok := c.calls[key]
+	if !ok {
+		call = &enrichmentCall{done: make(chan struct{})}
+		c.calls[key] = call
+		go c.run(context.WithoutCancel(ctx), key, call, group, song)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("%w: %w", entities.ErrUpstream, ctx.Err())
+	case <-call.done:
+	}
+
+	if call.err != nil {
+		return nil, call.err
+	}
+	// Каждый вызов получает свою копию, общий результат не меняется
+	info := *call.info
+	return &info, nil
+}
+
+// run выполняет общий запрос call и будит всех, кто его ждёт
+func (c *cachedEnricher) run(ctx context.Context, key string, call *enrichmentCall, group, song string) {
+	ctx, cancel := context.WithTimeout(ctx, c.lookupTimeout)
+	defer cancel()
+
+	call.info, call.err = c.lookup(ctx, group, song)
+
+	c.mu.Lock()
+	delete(c.calls, key)
+	c.mu.Unlock()
+	close(call.done)
+}
+
+// lookup ищет ответ в DB и при промахе запрашивает источники. Ошибки DB не мешают обогащению.
+func (c *cachedEnricher) lookup(ctx context.Context, group, song string) (*entities.ExternalSongInfo, error) {
+	entry, err := c.cache.GetEntry(ctx, group, song)
+	if err == nil {
+		if !entry.Found {
+			return nil, entities.ErrSongInfoNotFound
+		}
+		return &entry.Info, nil
+	}
+
+	info, err := c.enricher.GetSongInfo(ctx, group, song)
+	switch {
+	case err == nil:
+		c.cache.SaveEntry(ctx, entities.EnrichmentCacheEntry{Group: group, Title: song, Found: true, Info: *info}, c.ttl)
+	case errors.Is(err, entities.ErrSongInfoNotFound):
+		c.cache.SaveEntry(ctx, entities.EnrichmentCacheEntry{Group: group, Title: song}, c.notFoundTTL)
+	}
+	return info, err
+}
+
+func (c *cachedEnricher) Statuses() []entities.UpstreamStatus {
+	return c.enricher.Statuses()
+}
@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"TestEffectiveMobile/internal/entities"
+	"TestEffectiveMobile/internal/repository"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// blockingEnricher отвечает только после release и запоминает, был ли отменён его ctx
+type blockingEnricher struct {
+	EnricherRegistry
+	started  chan struct{}
+	release  chan struct{}
+	canceled chan error
+}
+
+func (e *blockingEnricher) GetSongInfo(ctx context.Context, group, song string) (*entities.ExternalSongInfo, error) {
+	close(e.started)
+	select {
+	case <-ctx.Done():
+		e.canceled <- ctx.Err()
+		return nil, ctx.Err()
+	case <-e.release:
+		e.canceled <- nil
+		return &entities.ExternalSongInfo{Text: "Paranoia"}, nil
+	}
+}
+
+// memoryCacheRepository кэш без записей, который запоминает сохранённые ответы
+type memoryCacheRepository struct {
+	repository.EnrichmentCacheRepository
+	saved chan entities.EnrichmentCacheEntry
+}
+
+func (r *memoryCacheRepository) GetEntry(ctx context.Context, group, song string) (*entities.EnrichmentCacheEntry, error) {
+	return nil, entities.ErrNotFound
+}
+
+func (r *memoryCacheRepository) SaveEntry(ctx context.Context, entry entities.EnrichmentCacheEntry, ttl time.Duration) error {
+	r.saved <- entry
+	return nil
+}
+
+func TestCachedEnricherLeaderCanceled(t *testing.T) {
+	enricher := &blockingEnricher{started: make(chan struct{}), release: make(chan struct{}), canceled: make(chan error, 1)}
+	cache := &memoryCacheRepository{saved: make(chan entities.EnrichmentCacheEntry, 1)}
+	c := NewCachedEnricher(enricher, cache, time.Hour, time.Hour, time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	leader := make(chan error, 1)
+	go func() {
+		_, err := c.GetSongInfo(ctx, "Muse", "Uprising")
+		leader <- err
+	}()
+	<-enricher.started
+
+	// Вызов, начавший запрос, уходит, а запрос к источникам продолжается для остальных
+	cancel()
+	if err := <-leader; !errors.Is(err, context.Canceled) {
+		t.Fatalf("ошибка отменённого вызова %v, ожидалась context.Canceled", err)
+	}
+	close(enricher.release)
+
+	if err := <-enricher.canceled; err != nil {
+		t.Fatalf("общий запрос отменён вместе с вызовом: %v", err)
+	}
+	if entry := <-cache.saved; !entry.Found || entry.Info.Text != "Paranoia" {
+		t.Errorf("сохранён ответ %+v, ожидался найденный ответ с текстом", entry)
+	}
+}
+
+func TestCachedEnricherLookupTimeout(t *testing.T) {
+	enricher := &blockingEnricher{started: make(chan struct{}), release: make(chan struct{}), canceled: make(chan error, 1)}
+	cache := &memoryCacheRepository{saved: make(chan entities.EnrichmentCacheEntry, 1)}
+	c := NewCachedEnricher(enricher, cache, time.Hour, time.Hour, 10*time.Millisecond)
+
+	// Общий запрос не наследует отмену вызова, поэтому его ограничивает собственный lookupTimeout
+	if _, err := c.GetSongInfo(context.Background(), "Muse", "Uprising"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ошибка %v, ожидалась context.DeadlineExceeded", err)
+	}
+	if err := <-enricher.canceled; !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("запрос к источникам завершился с %v, ожидалась context.DeadlineExceeded", err)
+	}
+}
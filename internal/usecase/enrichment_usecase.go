@@ -0,0 +1,226 @@
+package usecase
+
+import (
+	"TestEffectiveMobile/internal/entities"
+	"TestEffectiveMobile/internal/repository"
+	"TestEffectiveMobile/internal/songinfo"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+type EnrichmentUseCase interface {
+	Run(ctx context.Context)
+	GetSongEnrichment(ctx context.Context, songID int) (*entities.SongEnrichment, error)
+	ListFailedJobs(ctx context.Context, limit, offset int) ([]entities.EnrichmentJob, error)
+	RetryJob(ctx context.Context, id int) (*entities.EnrichmentJob, error)
+	UpstreamStatuses() []entities.UpstreamStatus
+	ListCacheEntries(ctx context.Context, group, song string, limit, offset int) ([]entities.EnrichmentCacheEntry, error)
+	DeleteCacheEntry(ctx context.Context, id int) error
+	InvalidateCache(ctx context.Context, group, song string, all bool) (int64, error)
+	PurgeExpiredCache(ctx context.Context) (int64, error)
+}
+
+type enrichmentUseCase struct {
+	repo         repository.EnrichmentRepository
+	enricher     EnricherRegistry
+	cache        repository.EnrichmentCacheRepository
+	workers      int
+	maxAttempts  int
+	pollInterval time.Duration
+	retryDelay   time.Duration
+	jobLease     time.Duration
+}
+
+// NewEnrichmentUseCase создаёт очередь обогащения песен. Задачи выполняют workers воркеров,
+// каждая задача получает не больше maxAttempts попыток с паузой retryDelay, растущей с каждой попыткой.
+func NewEnrichmentUseCase(
+	repo repository.EnrichmentRepository,
+	enricher EnricherRegistry,
+	cache repository.EnrichmentCacheRepository,
+	workers int,
+	maxAttempts int,
+	pollInterval time.Duration,
+	retryDelay time.Duration,
+	jobLease time.Duration,
+) EnrichmentUseCase {
+	return &enrichmentUseCase{
+		repo:         repo,
+		enricher:     enricher,
+		cache:        cache,
+		workers:      max(workers, 1),
+		maxAttempts:  max(maxAttempts, 1),
+		pollInterval: pollInterval,
+		retryDelay:   retryDelay,
+		jobLease:     jobLease,
+	}
+}
+
+// Run запускает воркеров и ждёт их остановки после отмены ctx
+func (u *enrichmentUseCase) Run(ctx context.Context) {
+	const op = "internal.useCase.RunEnrichment"
+
+	slog.Info(op+": воркеры обогащения запущены", "workers", u.workers)
+
+	var wg sync.WaitGroup
+	for i := 0; i < u.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			u.work(ctx)
+		}()
+	}
+	wg.Wait()
+
+	slog.Info(op + ": воркеры обогащения остановлены")
+}
+
+// work выполняет задачи одну за другой, а когда очередь пуста, проверяет её раз в pollInterval
+func (u *enrichmentUseCase) work(ctx context.Context) {
+	for {
+		if u.processNext(ctx) {
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(u.pollInterval):
+		}
+	}
+}
+
+// processNext выполняет одну задачу из очереди и сообщает, была ли задача
+func (u *enrichmentUseCase) processNext(ctx context.Context) bool {
+	const op = "internal.useCase.processNext"
+
+	job, err := u.repo.ClaimJob(ctx, u.jobLease)
+	if err != nil {
+		if !errors.Is(err, entities.ErrNotFound) && ctx.Err() == nil {
+			slog.Error(op, "Ошибка получения задачи обогащения", slog.String("error", err.Error()))
+		}
+		return false
+	}
+
+	song, err := u.enrich(ctx, job)
+	if err == nil {
+		err = u.repo.CompleteJob(ctx, job, song)
+	}
+	switch {
+	case err == nil:
+		slog.Info(op+": песня обогащена", "songID", job.SongID, "attempt", job.Attempts)
+	case ctx.Err() != nil:
+		// Воркер останавливается, задачу после истечения срока заберёт другой воркер
+		slog.Info(op+": обогащение прервано", "songID", job.SongID)
+	case errors.Is(err, entities.ErrConflict):
+		slog.Info(op+": задачу забрал другой воркер", "songID", job.SongID)
+	default:
+		u.retryLater(ctx, job, err)
+	}
+	return true
+}
+
+// enrich получает данные песни из источников обогащения
+func (u *enrichmentUseCase) enrich(ctx context.Context, job *entities.EnrichmentJob) (entities.Song, error) {
+	song := entities.Song{ID: job.SongID, Group: job.Group, Title: job.Title}
+
+	info, err := u.enricher.GetSongInfo(ctx, job.Group, job.Title)
+	if err != nil {
+		return song, err
+	}
+	song.ReleaseDate, song.Text, song.Link = info.ReleaseDate, info.Text, info.Link
+	if err = normalizeReleaseDate(&song.ReleaseDate, &song.ReleaseDatePrecision); err != nil {
+		// Дату вернул внешний API, поэтому это ошибка внешнего API, а не запроса
+		return song, fmt.Errorf("%w: %v", entities.ErrUpstream, err)
+	}
+	return song, nil
+}
+
+// retryLater откладывает задачу после неудачной попытки, а после последней попытки или если источники
+// не знают песню, завершает её с ошибкой
+func (u *enrichmentUseCase) retryLater(ctx context.Context, job *entities.EnrichmentJob, cause error) {
+	const op = "internal.useCase.retryLater"
+
+	var err error
+	if job.Attempts >= u.maxAttempts || errors.Is(cause, entities.ErrSongInfoNotFound) {
+		slog.Error(op, "Обогащение завершилось ошибкой",
+			slog.Int("songID", job.SongID), slog.String("error", cause.Error()))
+		err = u.repo.FailJob(ctx, job, cause.Error())
+	} else {
+		runAt := time.Now().Add(u.retryDelay * time.Duration(job.Attempts))
+		// Раньше, чем просит внешнее API или пока открыт выключатель, повторять нет смысла
+		var statusErr *songinfo.StatusError
+		if errors.As(cause, &statusErr) && time.Now().Add(statusErr.RetryAfter).After(runAt) {
+			runAt = time.Now().Add(statusErr.RetryAfter)
+		}
+		if errors.Is(cause, songinfo.ErrCircuitOpen) {
+			for _, status := range u.enricher.Statuses() {
+				if status.RetryAt != nil && status.RetryAt.After(runAt) {
+					runAt = *status.RetryAt
+				}
+			}
+		}
+		slog.Error(op, "Ошибка обогащения, попытка будет повторена",
+			slog.Int("songID", job.SongID), slog.Time("runAt", runAt), slog.String("error", cause.Error()))
+		err = u.repo.RescheduleJob(ctx, job, cause.Error(), runAt)
+	}
+	if err != nil && !errors.Is(err, entities.ErrConflict) {
+		slog.Error(op, "Ошибка сохранения результата задачи", slog.String("error", err.Error()))
+	}
+}
+
+func (u *enrichmentUseCase) GetSongEnrichment(ctx context.Context, songID int) (*entities.SongEnrichment, error) {
+	return u.repo.GetSongEnrichment(ctx, songID)
+}
+
+func (u *enrichmentUseCase) ListFailedJobs(ctx context.Context, limit, offset int) ([]entities.EnrichmentJob, error) {
+	return u.repo.ListFailedJobs(ctx, limit, offset)
+}
+
+// RetryJob возвращает завершившуюся ошибкой задачу в очередь
+func (u *enrichmentUseCase) RetryJob(ctx context.Context, id int) (*entities.EnrichmentJob, error) {
+	return u.repo.RetryJob(ctx, id)
+}
+
+// UpstreamStatuses возвращает состояние выключателей источников обогащения
+func (u *enrichmentUseCase) UpstreamStatuses() []entities.UpstreamStatus {
+	return u.enricher.Statuses()
+}
+
+// ListCacheEntries возвращает сохранённые ответы источников обогащения
+func (u *enrichmentUseCase) ListCacheEntries(ctx context.Context, group, song string, limit, offset int) ([]entities.EnrichmentCacheEntry, error) {
+	return u.cache.ListEntries(ctx, group, song, limit, offset)
+}
+
+func (u *enrichmentUseCase) DeleteCacheEntry(ctx context.Context, id int) error {
+	return u.cache.DeleteEntry(ctx, id)
+}
+
+// InvalidateCache удаляет сохранённые ответы группы и названия песни. Без группы и названия
+// удаляются все ответы, но только если это подтверждено флагом all.
+func (u *enrichmentUseCase) InvalidateCache(ctx context.Context, group, song string, all bool) (int64, error) {
+	if group == "" && song == "" && !all {
+		return 0, &entities.ValidationError{Fields: []entities.FieldError{
+			{Field: "group", Message: "укажите group или song, либо all=true для удаления всех записей"},
+		}}
+	}
+	return u.cache.DeleteEntries(ctx, group, song)
+}
+
+// PurgeExpiredCache удаляет сохранённые ответы, срок которых истёк
+func (u *enrichmentUseCase) PurgeExpiredCache(ctx context.Context) (int64, error) {
+	const op = "internal.useCase.PurgeExpiredCache"
+
+	purged, err := u.cache.DeleteExpired(ctx)
+	if err != nil {
+		return 0, err
+	}
+	slog.Info(op+": истёкшие ответы удалены", "purged", purged)
+	return purged, nil
+}
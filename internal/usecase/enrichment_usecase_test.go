@@ -0,0 +1,232 @@
+package usecase
+
+import (
+	"TestEffectiveMobile/internal/entities"
+	"TestEffectiveMobile/internal/mockinfo"
+	"TestEffectiveMobile/internal/repository"
+	"TestEffectiveMobile/internal/songinfo"
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// queueRepository очередь обогащения в памяти, которая запоминает результат каждой задачи
+type queueRepository struct {
+	repository.EnrichmentRepository
+
+	mu          sync.Mutex
+	jobs        []*entities.EnrichmentJob
+	completed   map[int]entities.Song
+	rescheduled map[int]string
+	failed      map[int]string
+}
+
+func newQueueRepository(jobs ...*entities.EnrichmentJob) *queueRepository {
+	return &queueRepository{
+		jobs:        jobs,
+		completed:   make(map[int]entities.Song),
+		rescheduled: make(map[int]string),
+		failed:      make(map[int]string),
+	}
+}
+
+func (r *queueRepository) ClaimJob(ctx context.Context, lease time.Duration) (*entities.EnrichmentJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.jobs) == 0 {
+		return nil, entities.ErrNotFound
+	}
+	job := r.jobs[0]
+	r.jobs = r.jobs[1:]
+	job.Attempts++
+	return job, nil
+}
+
+func (r *queueRepository) CompleteJob(ctx context.Context, job *entities.EnrichmentJob, song entities.Song) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.completed[job.SongID] = song
+	return nil
+}
+
+func (r *queueRepository) RescheduleJob(ctx context.Context, job *entities.EnrichmentJob, lastError string, runAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.rescheduled[job.SongID] = lastError
+	return nil
+}
+
+func (r *queueRepository) FailJob(ctx context.Context, job *entities.EnrichmentJob, lastError string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.failed[job.SongID] = lastError
+	return nil
+}
+
+var testFixtures = []mockinfo.Fixture{
+	{Group: "Muse", Song: "Uprising", ReleaseDate: "16.07.2009", Text: "Paranoia is in bloom", Link: "https://example.com/uprising"},
+	{Group: "Muse", Song: "Resistance", ReleaseDate: "2009", Text: "Is our secret safe tonight", Link: "https://example.com/resistance"},
+}
+
+// newMockEnrichmentUseCase создаёт очередь обогащения с клиентом внешнего API, запущенного в процессе
+func newMockEnrichmentUseCase(t *testing.T, server *mockinfo.Server, repo repository.EnrichmentRepository, maxAttempts int) *enrichmentUseCase {
+	t.Helper()
+
+	client := songinfo.NewClient(
+		server.URL,
+		time.Second,
+		songinfo.RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond},
+		songinfo.NewCircuitBreaker(10, time.Minute),
+	)
+	registry, err := NewEnricherRegistry([]EnrichmentProvider{{Name: "mock", Enricher: client}}, EnrichmentModePriority, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewEnrichmentUseCase(repo, registry, nil, 1, maxAttempts, time.Second, time.Second, time.Minute).(*enrichmentUseCase)
+}
+
+func TestProcessNext(t *testing.T) {
+	tests := []struct {
+		name          string
+		song          string
+		attempts      int
+		fault         *mockinfo.Fault
+		wantText      string
+		wantRetry     bool
+		wantFail      string
+		wantRequests  int64
+		wantErrSubstr string
+	}{
+		{
+			name:         "песня найдена",
+			song:         "Uprising",
+			wantText:     "Paranoia is in bloom",
+			wantRequests: 1,
+		},
+		{
+			name:         "404 завершает задачу без повторов",
+			song:         "Hysteria",
+			wantFail:     entities.ErrSongInfoNotFound.Error(),
+			wantRequests: 1,
+		},
+		{
+			name:         "короткая серия 503 переживается повторами клиента",
+			song:         "Uprising",
+			fault:        &mockinfo.Fault{Status: http.StatusServiceUnavailable, Count: 2},
+			wantText:     "Paranoia is in bloom",
+			wantRequests: 3,
+		},
+		{
+			name:          "долгая серия 503 откладывает задачу",
+			song:          "Uprising",
+			fault:         &mockinfo.Fault{Status: http.StatusServiceUnavailable},
+			wantRetry:     true,
+			wantRequests:  3,
+			wantErrSubstr: "503",
+		},
+		{
+			name:          "503 на последней попытке завершает задачу",
+			song:          "Uprising",
+			attempts:      2,
+			fault:         &mockinfo.Fault{Status: http.StatusServiceUnavailable},
+			wantFail:      "503",
+			wantRequests:  3,
+			wantErrSubstr: "503",
+		},
+		{
+			name:          "неверное тело откладывает задачу без повторов клиента",
+			song:          "Uprising",
+			fault:         &mockinfo.Fault{Malformed: true},
+			wantRetry:     true,
+			wantRequests:  1,
+			wantErrSubstr: "неверный ответ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := mockinfo.Start(testFixtures)
+			defer server.Close()
+			if tt.fault != nil {
+				server.AddFault(*tt.fault)
+			}
+
+			repo := newQueueRepository(&entities.EnrichmentJob{ID: 1, SongID: 7, Group: "Muse", Title: tt.song, Attempts: tt.attempts})
+			u := newMockEnrichmentUseCase(t, server, repo, 3)
+
+			if !u.processNext(context.Background()) {
+				t.Fatal("processNext() не взял задачу")
+			}
+			if u.processNext(context.Background()) {
+				t.Fatal("processNext() взял задачу из пустой очереди")
+			}
+
+			if song, ok := repo.completed[7]; ok != (tt.wantText != "") || song.Text != tt.wantText {
+				t.Errorf("выполненная задача %+v (%v), ожидался текст %q", song, ok, tt.wantText)
+			}
+			lastError, retried := repo.rescheduled[7]
+			if retried != tt.wantRetry {
+				t.Errorf("задача отложена: %v, ожидалось %v", retried, tt.wantRetry)
+			}
+			if retried && !strings.Contains(lastError, tt.wantErrSubstr) {
+				t.Errorf("ошибка отложенной задачи %q, ожидалась %q", lastError, tt.wantErrSubstr)
+			}
+			if failError, failed := repo.failed[7]; failed != (tt.wantFail != "") || !strings.Contains(failError, tt.wantFail) {
+				t.Errorf("ошибка завершённой задачи %q (%v), ожидалась %q", failError, failed, tt.wantFail)
+			}
+			if requests := server.Requests(); requests != tt.wantRequests {
+				t.Errorf("запросов к внешнему API %d, ожидалось %d", requests, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestImportThenEnrich(t *testing.T) {
+	server := mockinfo.Start(testFixtures)
+	defer server.Close()
+
+	songs := &importSongRepository{created: make(map[string]entities.Song)}
+	rows := []entities.ImportRow{
+		{Line: 2, Song: entities.Song{Group: "Muse", Title: "Resistance"}},
+		{Line: 3, Song: entities.Song{Group: "Muse", Title: "Hysteria", Text: "It's bugging me"}},
+	}
+	report := NewSongUseCase(songs, time.Hour, 2, time.Minute).ImportSongs(context.Background(), rows, false)
+	if report.Created != 2 {
+		t.Fatalf("создано %d песен, ожидалось 2: %+v", report.Created, report.Rows)
+	}
+	// Импорт только ставит песни в очередь, внешний API вызывает воркер
+	if requests := server.Requests(); requests != 0 {
+		t.Fatalf("импорт обратился к внешнему API %d раз", requests)
+	}
+
+	// Задачи создаёт репозиторий для песен со статусом pending, здесь они собираются из созданных песен
+	var jobs []*entities.EnrichmentJob
+	for i, row := range rows {
+		song := songs.created[row.Song.Title]
+		if song.EnrichmentStatus != entities.EnrichmentPending {
+			t.Fatalf("%s: статус обогащения %q, ожидался pending", song.Title, song.EnrichmentStatus)
+		}
+		jobs = append(jobs, &entities.EnrichmentJob{ID: i + 1, SongID: i + 1, Group: song.Group, Title: song.Title})
+	}
+	queue := newQueueRepository(jobs...)
+	u := newMockEnrichmentUseCase(t, server, queue, 3)
+	for u.processNext(context.Background()) {
+	}
+
+	if song := queue.completed[1]; song.Text != "Is our secret safe tonight" || song.ReleaseDate != "2009" {
+		t.Errorf("Resistance обогащена как %+v", song)
+	}
+	if lastError := queue.failed[2]; lastError != entities.ErrSongInfoNotFound.Error() {
+		t.Errorf("Hysteria: ошибка %q, ожидалась %q", lastError, entities.ErrSongInfoNotFound)
+	}
+	if requests := server.Requests(); requests != 2 {
+		t.Errorf("запросов к внешнему API %d, ожидалось 2", requests)
+	}
+}
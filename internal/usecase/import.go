@@ -0,0 +1,158 @@
+package usecase
+
+import (
+	"TestEffectiveMobile/internal/entities"
+	"TestEffectiveMobile/internal/validation"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// ImportSongs создаёт песни из строк файла импорта не более чем в importConcurrency потоков.
+// Песни с незаполненными данными сохраняются со статусом обогащения pending, как при CreateSong,
+// и их дополняет воркер очереди. В режиме dryRun строки только проверяются на ошибки и дубликаты.
+func (u *songUseCase) ImportSongs(ctx context.Context, rows []entities.ImportRow, dryRun bool) entities.ImportReport {
+	const op = "internal.useCase.ImportSongs"
+
+	ctx, cancel := context.WithTimeout(ctx, u.importTimeout)
+	defer cancel()
+
+	report := entities.ImportReport{
+		DryRun: dryRun,
+		Total:  len(rows),
+		Rows:   make([]entities.ImportRowResult, len(rows)),
+	}
+
+	// Дубликаты внутри файла определяются заранее, чтобы результат не зависел от порядка потоков
+	seen := make(map[string]int, len(rows))
+	duplicateOf := make([]int, len(rows))
+	for i, row := range rows {
+		if row.Err != nil {
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(row.Song.Group)) + "\x00" + strings.ToLower(strings.TrimSpace(row.Song.Title))
+		if first, ok := seen[key]; ok {
+			duplicateOf[i] = rows[first].Line
+			continue
+		}
+		seen[key] = i
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, u.importConcurrency)
+	for i, row := range rows {
+		report.Rows[i] = entities.ImportRowResult{
+			Line:  row.Line,
+			Group: row.Song.Group,
+			Title: row.Song.Title,
+		}
+		if duplicateOf[i] != 0 {
+			report.Rows[i].Status = entities.ImportSkippedDuplicate
+			report.Rows[i].Error = fmt.Sprintf("повторяет строку %d", duplicateOf[i])
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(result *entities.ImportRowResult, row entities.ImportRow) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			u.importRow(ctx, result, row, dryRun)
+		}(&report.Rows[i], row)
+	}
+	wg.Wait()
+
+	for _, result := range report.Rows {
+		switch result.Status {
+		case entities.ImportCreated, entities.ImportWouldCreate:
+			report.Created++
+		case entities.ImportSkippedDuplicate:
+			report.Skipped++
+		default:
+			report.Failed++
+		}
+	}
+	slog.Info(op+": импорт завершён",
+		"total", report.Total,
+		"created", report.Created,
+		"skipped", report.Skipped,
+		"failed", report.Failed,
+		"dryRun", dryRun,
+	)
+	return report
+}
+
+// importRow обрабатывает одну строку импорта и записывает результат
+func (u *songUseCase) importRow(ctx context.Context, result *entities.ImportRowResult, row entities.ImportRow, dryRun bool) {
+	fail := func(err error) {
+		result.Status = entities.ImportFailed
+		result.Error = err.Error()
+	}
+
+	if row.Err != nil {
+		fail(row.Err)
+		return
+	}
+	// Строки, до которых не дошла очередь до истечения времени импорта, не обрабатываются
+	if err := ctx.Err(); err != nil {
+		fail(err)
+		return
+	}
+	song := row.Song
+	if err := validation.Song(song); err != nil {
+		fail(err)
+		return
+	}
+
+	existing, err := u.repo.FindSong(ctx, song.Group, song.Title)
+	switch {
+	case err == nil:
+		result.Status = entities.ImportSkippedDuplicate
+		result.ID = existing.ID
+		return
+	case !errors.Is(err, entities.ErrNotFound):
+		fail(err)
+		return
+	}
+
+	if dryRun {
+		if err = normalizeReleaseDate(&song.ReleaseDate, &song.ReleaseDatePrecision); err != nil {
+			fail(err)
+			return
+		}
+		result.Status = entities.ImportWouldCreate
+		return
+	}
+
+	song.EnrichmentStatus = enrichmentStatusFor(song)
+	if err = normalizeReleaseDate(&song.ReleaseDate, &song.ReleaseDatePrecision); err != nil {
+		fail(err)
+		return
+	}
+
+	id, err := u.repo.CreateSong(ctx, song)
+	if errors.Is(err, entities.ErrDuplicateSong) {
+		// Песню успели добавить параллельно с импортом
+		result.Status = entities.ImportSkippedDuplicate
+		return
+	}
+	if err != nil {
+		fail(err)
+		return
+	}
+	result.Status = entities.ImportCreated
+	result.ID = id
+}
+
+// enrichmentStatusFor возвращает статус обогащения новой песни. Воркер очереди заполняет
+// только пустые поля, поэтому данные клиента сохраняются, а песня без пустых полей
+// сразу получает статус done. Статус, пришедший вместе с песней, не учитывается.
+func enrichmentStatusFor(song entities.Song) entities.EnrichmentStatus {
+	if song.ReleaseDate == "" || song.Text == "" || song.Link == "" {
+		return entities.EnrichmentPending
+	}
+	return entities.EnrichmentDone
+}
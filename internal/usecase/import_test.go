@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"TestEffectiveMobile/internal/entities"
+	"TestEffectiveMobile/internal/repository"
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// importSongRepository запоминает созданные песни, в библиотеке есть только Muse - Uprising
+type importSongRepository struct {
+	repository.SongRepository
+
+	mu      sync.Mutex
+	created map[string]entities.Song
+}
+
+func (r *importSongRepository) FindSong(ctx context.Context, group, title string) (*entities.Song, error) {
+	if group == "Muse" && title == "Uprising" {
+		return &entities.Song{ID: 1, Group: group, Title: title}, nil
+	}
+	return nil, entities.ErrNotFound
+}
+
+func (r *importSongRepository) CreateSong(ctx context.Context, song entities.Song) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.created[song.Title] = song
+	return len(r.created) + 1, nil
+}
+
+func TestImportSongsEnqueuesEnrichment(t *testing.T) {
+	repo := &importSongRepository{created: make(map[string]entities.Song)}
+	// Обогащение при импорте не выполняется, поэтому песням хватает репозитория без enricher
+	useCase := NewSongUseCase(repo, time.Hour, 2, time.Minute)
+
+	rows := []entities.ImportRow{
+		{Line: 2, Song: entities.Song{Group: "Muse", Title: "Uprising"}},
+		{Line: 3, Song: entities.Song{Group: "Muse", Title: "Resistance"}},
+		{Line: 4, Song: entities.Song{Group: "Muse", Title: "Starlight", ReleaseDate: "03.09.2006", Text: "Far away", Link: "https://example.com"}},
+		// Статус обогащения из строки не учитывается, его выставляет сценарий
+		{Line: 5, Song: entities.Song{Group: "Muse", Title: "Hysteria", Text: "It's bugging me", EnrichmentStatus: entities.EnrichmentDone}},
+	}
+	report := useCase.ImportSongs(context.Background(), rows, false)
+
+	if report.Created != 3 || report.Skipped != 1 || report.Failed != 0 {
+		t.Fatalf("created %d, skipped %d, failed %d, ожидалось 3, 1, 0: %+v",
+			report.Created, report.Skipped, report.Failed, report.Rows)
+	}
+
+	tests := []struct {
+		title      string
+		wantStatus entities.EnrichmentStatus
+		wantText   string
+	}{
+		{title: "Resistance", wantStatus: entities.EnrichmentPending},
+		{title: "Starlight", wantStatus: entities.EnrichmentDone, wantText: "Far away"},
+		{title: "Hysteria", wantStatus: entities.EnrichmentPending, wantText: "It's bugging me"},
+	}
+	for _, tt := range tests {
+		song, ok := repo.created[tt.title]
+		if !ok {
+			t.Errorf("%s: песня не создана", tt.title)
+			continue
+		}
+		if song.EnrichmentStatus != tt.wantStatus {
+			t.Errorf("%s: статус обогащения %q, ожидался %q", tt.title, song.EnrichmentStatus, tt.wantStatus)
+		}
+		if song.Text != tt.wantText {
+			t.Errorf("%s: текст %q, ожидался %q", tt.title, song.Text, tt.wantText)
+		}
+	}
+	if date := repo.created["Starlight"].ReleaseDate; date != "2006-09-03" {
+		t.Errorf("Starlight: дата выпуска %q, ожидалась 2006-09-03", date)
+	}
+}
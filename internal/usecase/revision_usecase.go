@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"TestEffectiveMobile/internal/entities"
+	"TestEffectiveMobile/internal/repository"
+	"context"
+)
+
+type RevisionUseCase interface {
+	ListRevisions(ctx context.Context, songID, limit, offset int) ([]entities.SongRevision, error)
+	GetRevision(ctx context.Context, songID, revision int) (*entities.SongRevision, error)
+	RevertSong(ctx context.Context, songID, revision, version int) error
+	DiffRevisions(ctx context.Context, songID, from, to int) (*entities.SongRevisionDiff, error)
+}
+
+type revisionUseCase struct {
+	repo     repository.RevisionRepository
+	songRepo repository.SongRepository
+}
+
+func NewRevisionUseCase(repo repository.RevisionRepository, songRepo repository.SongRepository) RevisionUseCase {
+	return &revisionUseCase{
+		repo:     repo,
+		songRepo: songRepo,
+	}
+}
+
+func (u *revisionUseCase) ListRevisions(ctx context.Context, songID, limit, offset int) ([]entities.SongRevision, error) {
+	return u.repo.ListRevisions(ctx, songID, limit, offset)
+}
+
+func (u *revisionUseCase) GetRevision(ctx context.Context, songID, revision int) (*entities.SongRevision, error) {
+	return u.repo.GetRevision(ctx, songID, revision)
+}
+
+// RevertSong записывает в песню данные из ревизии, при этом создаётся новая ревизия.
+// version - ожидаемая версия песни, 0 отключает проверку.
+func (u *revisionUseCase) RevertSong(ctx context.Context, songID, revision, version int) error {
+	rev, err := u.repo.GetRevision(ctx, songID, revision)
+	if err != nil {
+		return err
+	}
+	rev.Snapshot.Version = version
+	return u.songRepo.UpdateSong(ctx, rev.Snapshot)
+}
+
+func (u *revisionUseCase) DiffRevisions(ctx context.Context, songID, from, to int) (*entities.SongRevisionDiff, error) {
+	fromRev, err := u.repo.GetRevision(ctx, songID, from)
+	if err != nil {
+		return nil, err
+	}
+	toRev, err := u.repo.GetRevision(ctx, songID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := diffLines(fromRev.Snapshot.Text, toRev.Snapshot.Text)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entities.SongRevisionDiff{
+		SongID: songID,
+		From:   from,
+		To:     to,
+		Lines:  lines,
+	}, nil
+}
@@ -3,107 +3,274 @@ package usecase
 import (
 	"TestEffectiveMobile/internal/entities"
 	"TestEffectiveMobile/internal/repository"
-	"encoding/json"
+	"TestEffectiveMobile/internal/validation"
+	"context"
+	"errors"
 	"fmt"
-	"io"
 	"log/slog"
-	"net/http"
-	"net/url"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type SongUseCase interface {
-	ListSongs(filter map[string]string, limit, offset int) ([]entities.Song, error)
-	DeleteSong(id int) error
-	UpdateSong(song entities.Song) error
-	CreateSong(song entities.Song) (int, error)
-	GetSongByID(id int) (*entities.Song, error)
+	ListSongs(ctx context.Context, filter entities.SongFilter, sort entities.SongSort, limit, offset int) ([]entities.Song, error)
+	ListSongsAfter(
+		ctx context.Context,
+		filter entities.SongFilter,
+		sort entities.SongSort,
+		cursor *entities.Cursor,
+		limit int,
+	) ([]entities.Song, *entities.Cursor, error)
+	CountSongs(ctx context.Context, filter entities.SongFilter) (int, error)
+	ExportSongs(
+		ctx context.Context,
+		filter entities.SongFilter,
+		sort entities.SongSort,
+		withText bool,
+		fn func(song entities.Song) error,
+	) error
+	DeleteSong(ctx context.Context, id, version int) error
+	ListTrash(ctx context.Context, limit, offset int) ([]entities.Song, error)
+	RestoreSong(ctx context.Context, id int) error
+	PurgeTrash(ctx context.Context) (int64, error)
+	UpdateSong(ctx context.Context, song entities.Song) error
+	PatchSong(ctx context.Context, id int, patch entities.SongPatch, version int) error
+	CreateSong(ctx context.Context, song entities.Song, upsert bool) (int, bool, error)
+	ImportSongs(ctx context.Context, rows []entities.ImportRow, dryRun bool) entities.ImportReport
+	GetSongByID(ctx context.Context, id int) (*entities.Song, error)
+	GetSongDetail(ctx context.Context, id int, includes entities.SongIncludes) (*entities.SongDetail, error)
+	ListDuplicates(ctx context.Context, threshold float64, limit, offset int) ([]entities.SongDuplicate, error)
+	MergeSongs(ctx context.Context, targetID, sourceID, version int) error
 	GetSongText(song *entities.Song, versePage, versePageSize int) (string, error)
+	SearchSongs(ctx context.Context, q string, limit, offset int) ([]entities.SongSearchResult, error)
 }
 
 type songUseCase struct {
-	repo           repository.SongRepository
-	externalAPIURL string
+	repo              repository.SongRepository
+	trashRetention    time.Duration
+	importConcurrency int
+	importTimeout     time.Duration
 }
 
-func NewSongUseCase(repo repository.SongRepository, externalAPIURL string) SongUseCase {
+// NewSongUseCase создаёт сценарии работы с песнями. importTimeout ограничивает время всего импорта.
+func NewSongUseCase(
+	repo repository.SongRepository,
+	trashRetention time.Duration,
+	importConcurrency int,
+	importTimeout time.Duration,
+) SongUseCase {
 	return &songUseCase{
-		repo:           repo,
-		externalAPIURL: externalAPIURL,
+		repo:              repo,
+		trashRetention:    trashRetention,
+		importConcurrency: max(importConcurrency, 1),
+		importTimeout:     importTimeout,
 	}
 }
 
-func (u *songUseCase) ListSongs(filter map[string]string, limit, offset int) ([]entities.Song, error) {
-	return u.repo.ListSongs(filter, limit, offset)
+func (u *songUseCase) ListSongs(
+	ctx context.Context,
+	filter entities.SongFilter,
+	sort entities.SongSort,
+	limit, offset int,
+) ([]entities.Song, error) {
+	return u.repo.ListSongs(ctx, filter, sort, limit, offset)
 }
 
-func (u *songUseCase) DeleteSong(id int) error {
-	return u.repo.DeleteSong(id)
+func (u *songUseCase) ListSongsAfter(
+	ctx context.Context,
+	filter entities.SongFilter,
+	sort entities.SongSort,
+	cursor *entities.Cursor,
+	limit int,
+) ([]entities.Song, *entities.Cursor, error) {
+	return u.repo.ListSongsAfter(ctx, filter, sort, cursor, limit)
 }
 
-func (u *songUseCase) UpdateSong(song entities.Song) error {
-	return u.repo.UpdateSong(song)
+func (u *songUseCase) CountSongs(ctx context.Context, filter entities.SongFilter) (int, error) {
+	return u.repo.CountSongs(ctx, filter)
 }
 
-func (u *songUseCase) CreateSong(song entities.Song) (int, error) {
-	const op = "internal.useCase.CreateSong"
+func (u *songUseCase) ExportSongs(
+	ctx context.Context,
+	filter entities.SongFilter,
+	sort entities.SongSort,
+	withText bool,
+	fn func(song entities.Song) error,
+) error {
+	return u.repo.ExportSongs(ctx, filter, sort, withText, fn)
+}
 
-	// Вызываем внешний API для обогащения
-	enriched, err := u.getSongInfo(song.Group, song.Title)
-	if err != nil {
-		slog.Error(op, "Ошибка внешнего API", slog.String("error", err.Error()))
-		return 0, err
+func (u *songUseCase) ListDuplicates(ctx context.Context, threshold float64, limit, offset int) ([]entities.SongDuplicate, error) {
+	return u.repo.ListDuplicates(ctx, threshold, limit, offset)
+}
+
+// MergeSongs объединяет песню sourceID с песней targetID
+func (u *songUseCase) MergeSongs(ctx context.Context, targetID, sourceID, version int) error {
+	if targetID == sourceID {
+		return fmt.Errorf("%w: песню нельзя объединить саму с собой", entities.ErrValidation)
 	}
-	song.ReleaseDate = enriched.ReleaseDate
-	song.Text = enriched.Text
-	song.Link = enriched.Link
+	return u.repo.MergeSongs(ctx, targetID, sourceID, version)
+}
 
-	return u.repo.CreateSong(song)
+func (u *songUseCase) SearchSongs(ctx context.Context, q string, limit, offset int) ([]entities.SongSearchResult, error) {
+	return u.repo.SearchSongs(ctx, q, limit, offset)
 }
 
-func (u *songUseCase) getSongInfo(group, song string) (*entities.ExternalSongInfo, error) {
-	const op = "internal.useCase.GetSongInfo"
+func (u *songUseCase) DeleteSong(ctx context.Context, id, version int) error {
+	return u.repo.DeleteSong(ctx, id, version)
+}
+
+func (u *songUseCase) ListTrash(ctx context.Context, limit, offset int) ([]entities.Song, error) {
+	return u.repo.ListTrash(ctx, limit, offset)
+}
+
+func (u *songUseCase) RestoreSong(ctx context.Context, id int) error {
+	return u.repo.RestoreSong(ctx, id)
+}
 
-	params := url.Values{}
-	params.Add("group", group)
-	params.Add("song", song)
-	fullURL := fmt.Sprintf("%s?%s", u.externalAPIURL, params.Encode())
+// PurgeTrash окончательно удаляет песни, пролежавшие в корзине дольше срока хранения
+func (u *songUseCase) PurgeTrash(ctx context.Context) (int64, error) {
+	const op = "internal.useCase.PurgeTrash"
 
-	resp, err := http.Get(fullURL)
+	purged, err := u.repo.PurgeTrash(ctx, time.Now().Add(-u.trashRetention))
 	if err != nil {
-		slog.Error(op, "Ошибка запроса обогащения", slog.String("error", err.Error()))
-		return nil, err
+		return 0, err
 	}
-	defer resp.Body.Close()
+	slog.Info(op+": корзина очищена", "purged", purged)
+	return purged, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		err = fmt.Errorf("внешнее API вернуло статус %d", resp.StatusCode)
-		slog.Error(op, "Не тот статус код", slog.String("error", err.Error()))
-		return nil, err
+func (u *songUseCase) UpdateSong(ctx context.Context, song entities.Song) error {
+	if err := validation.Song(song); err != nil {
+		return err
+	}
+	if err := normalizeReleaseDate(&song.ReleaseDate, &song.ReleaseDatePrecision); err != nil {
+		return err
 	}
+	return u.repo.UpdateSong(ctx, song)
+}
 
-	body, err := io.ReadAll(resp.Body)
+// normalizeReleaseDate приводит дату выпуска к ISO 8601 и определяет её точность
+func normalizeReleaseDate(value *string, precision *entities.DatePrecision) error {
+	if *value == "" {
+		*precision = ""
+		return nil
+	}
+	date, err := entities.ParseReleaseDate(*value)
 	if err != nil {
-		slog.Error(op, "Ошибка парсинга тела ответа", slog.String("error", err.Error()))
-		return nil, err
+		return fmt.Errorf("%w: %w", entities.ErrInvalidReleaseDate, err)
 	}
+	*value = date.String()
+	*precision = date.Precision
+	return nil
+}
 
-	var info entities.ExternalSongInfo
-	if err = json.Unmarshal(body, &info); err != nil {
-		slog.Error(op, "Ошибка анмаршалинга данных", slog.String("error", err.Error()))
+// PatchSong изменяет только переданные в патче поля песни
+func (u *songUseCase) PatchSong(ctx context.Context, id int, patch entities.SongPatch, version int) error {
+	if err := validation.SongPatch(patch); err != nil {
+		return err
+	}
+	if patch.ReleaseDate != nil {
+		var precision entities.DatePrecision
+		if err := normalizeReleaseDate(patch.ReleaseDate, &precision); err != nil {
+			return err
+		}
+	}
+	return u.repo.PatchSong(ctx, id, patch, version)
+}
+
+// CreateSong добавляет песню и возвращает её ID и признак того, что песня создана.
+// Переданные клиентом дата выпуска, текст и ссылка сохраняются. Если какое-то из этих полей пустое,
+// песня получает статус обогащения pending и недостающие данные заполняет воркер очереди.
+// Если песня с такими группой и названием уже есть, возвращается *entities.DuplicateSongError,
+// а при upsert существующая песня ставится в очередь на обновление данными из внешнего API.
+func (u *songUseCase) CreateSong(ctx context.Context, song entities.Song, upsert bool) (int, bool, error) {
+	if err := validation.Song(song); err != nil {
+		return 0, false, err
+	}
+
+	existing, err := u.repo.FindSong(ctx, song.Group, song.Title)
+	switch {
+	case err == nil && !upsert:
+		return 0, false, &entities.DuplicateSongError{ID: existing.ID}
+	case err == nil:
+		return existing.ID, false, u.repo.EnqueueEnrichment(ctx, existing.ID, true)
+	case !errors.Is(err, entities.ErrNotFound):
+		return 0, false, err
+	}
+
+	// Как и при импорте, воркер очереди заполняет только поля, которые клиент оставил пустыми
+	song.EnrichmentStatus = enrichmentStatusFor(song)
+	if err = normalizeReleaseDate(&song.ReleaseDate, &song.ReleaseDatePrecision); err != nil {
+		return 0, false, err
+	}
+
+	id, err := u.repo.CreateSong(ctx, song)
+	if errors.Is(err, entities.ErrDuplicateSong) {
+		// Песню успели добавить параллельным запросом
+		if existing, err = u.repo.FindSong(ctx, song.Group, song.Title); err != nil {
+			return 0, false, err
+		}
+		return 0, false, &entities.DuplicateSongError{ID: existing.ID}
+	}
+	return id, err == nil, err
+}
+
+func (u *songUseCase) GetSongByID(ctx context.Context, id int) (*entities.Song, error) {
+	return u.repo.GetSongByID(ctx, id)
+}
+
+// GetSongDetail возвращает песню и связанные данные, запрошенные в includes
+func (u *songUseCase) GetSongDetail(ctx context.Context, id int, includes entities.SongIncludes) (*entities.SongDetail, error) {
+	song, err := u.repo.GetSongByID(ctx, id)
+	if err != nil {
 		return nil, err
 	}
+	detail := &entities.SongDetail{Song: *song}
 
-	return &info, nil
+	if includes[entities.IncludeVerses] {
+		verseCount := countVerses(song.Text)
+		detail.VerseCount = &verseCount
+	}
+	if includes[entities.IncludeRevisions] {
+		revisionCount, err := u.repo.CountRevisions(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		detail.RevisionCount = &revisionCount
+	}
+	if includes[entities.IncludeArtist] {
+		var filter entities.SongFilter
+		if err = filter.Add(entities.FieldArtistID, entities.OpEq, strconv.Itoa(song.ArtistID)); err != nil {
+			return nil, err
+		}
+		songCount, err := u.repo.CountSongs(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		detail.Artist = &entities.ArtistSummary{
+			Artist:    *entities.NewArtist(song.ArtistID, song.Group),
+			SongCount: songCount,
+		}
+	}
+	return detail, nil
 }
 
-func (u *songUseCase) GetSongByID(id int) (*entities.Song, error) {
-	return u.repo.GetSongByID(id)
+// countVerses считает куплеты так же, как их разбивает на страницы GetSongText
+func countVerses(text string) int {
+	if text == "" {
+		return 0
+	}
+	return len(strings.Split(text, "\n"))
 }
 
 func (u *songUseCase) GetSongText(song *entities.Song, versePage, versePageSize int) (string, error) {
 	const op = "internal.useCase.GetSongText"
 
+	if versePage < 1 || versePageSize < 1 {
+		return "", fmt.Errorf("%w: номер и размер страницы куплетов должны быть положительными", entities.ErrValidation)
+	}
 	if song.Text == "" {
 		slog.Info("Отсутствует текст песни", "songID", song.ID)
 		return "", nil
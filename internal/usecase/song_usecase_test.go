@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"TestEffectiveMobile/internal/entities"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCreateSongKeepsClientFields(t *testing.T) {
+	tests := []struct {
+		name       string
+		song       entities.Song
+		wantStatus entities.EnrichmentStatus
+		wantDate   string
+		wantText   string
+	}{
+		{
+			name:       "только группа и название",
+			song:       entities.Song{Group: "Muse", Title: "Resistance"},
+			wantStatus: entities.EnrichmentPending,
+		},
+		{
+			name:       "заполнена часть полей",
+			song:       entities.Song{Group: "Muse", Title: "Hysteria", Text: "It's bugging me"},
+			wantStatus: entities.EnrichmentPending,
+			wantText:   "It's bugging me",
+		},
+		{
+			name: "заполнены все поля",
+			song: entities.Song{
+				Group: "Muse", Title: "Starlight", ReleaseDate: "03.09.2006", Text: "Far away", Link: "https://example.com",
+			},
+			wantStatus: entities.EnrichmentDone,
+			wantDate:   "2006-09-03",
+			wantText:   "Far away",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &importSongRepository{created: make(map[string]entities.Song)}
+			useCase := NewSongUseCase(repo, time.Hour, 2, time.Minute)
+
+			if _, created, err := useCase.CreateSong(context.Background(), tt.song, false); err != nil || !created {
+				t.Fatalf("CreateSong: created %t, ошибка %v", created, err)
+			}
+			song := repo.created[tt.song.Title]
+			if song.EnrichmentStatus != tt.wantStatus {
+				t.Errorf("статус обогащения %q, ожидался %q", song.EnrichmentStatus, tt.wantStatus)
+			}
+			if song.ReleaseDate != tt.wantDate {
+				t.Errorf("дата выпуска %q, ожидалась %q", song.ReleaseDate, tt.wantDate)
+			}
+			if song.Text != tt.wantText {
+				t.Errorf("текст %q, ожидался %q", song.Text, tt.wantText)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package validation
+
+import "TestEffectiveMobile/internal/entities"
+
+// Длины полей совпадают с размерами колонок в DB
+const (
+	maxNameLength  = 255
+	maxTitleLength = 255
+	maxLinkLength  = 255
+)
+
+// fieldRules правила проверки поля, name имя поля в JSON
+type fieldRules struct {
+	name  string
+	rules []Rule
+}
+
+// Правила проверки полей песни в порядке, в котором поля перечисляются в ошибке
+var songRules = []fieldRules{
+	{name: "group", rules: []Rule{Required, MaxLength(maxNameLength)}},
+	{name: "song", rules: []Rule{Required, MaxLength(maxTitleLength)}},
+	{name: "releaseDate", rules: []Rule{ReleaseDate}},
+	{name: "link", rules: []Rule{MaxLength(maxLinkLength), URL}},
+}
+
+// Правила проверки названия исполнителя
+var artistNameRules = []Rule{Required, MaxLength(maxNameLength)}
+
+// Song проверяет песню перед созданием или полной заменой
+func Song(song entities.Song) error {
+	fields := make([]Field, 0, len(songRules))
+	for _, field := range songRules {
+		value, _ := entities.SongField(&song, field.name)
+		fields = append(fields, Field{Name: field.name, Value: value, Rules: field.rules})
+	}
+	return Validate(fields...)
+}
+
+// SongPatch проверяет только переданные в патче поля песни
+func SongPatch(patch entities.SongPatch) error {
+	var fields []Field
+	for _, field := range songRules {
+		if value := *patch.Field(field.name); value != nil {
+			fields = append(fields, Field{Name: field.name, Value: *value, Rules: field.rules})
+		}
+	}
+	return Validate(fields...)
+}
+
+// Artist проверяет исполнителя перед созданием или переименованием
+func Artist(artist entities.Artist) error {
+	return Validate(Field{Name: "name", Value: artist.Name, Rules: artistNameRules})
+}
@@ -0,0 +1,81 @@
+package validation
+
+import (
+	"TestEffectiveMobile/internal/entities"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// Rule проверяет значение поля и возвращает причину ошибки или пустую строку
+type Rule func(value string) string
+
+// Field значение поля запроса с правилами его проверки
+type Field struct {
+	Name  string
+	Value string
+	Rules []Rule
+}
+
+// Validate проверяет все поля и возвращает *entities.ValidationError со всеми нарушениями сразу.
+// Для каждого поля сообщается только первое нарушенное правило.
+func Validate(fields ...Field) error {
+	var errs []entities.FieldError
+	for _, field := range fields {
+		for _, rule := range field.Rules {
+			if message := rule(field.Value); message != "" {
+				errs = append(errs, entities.FieldError{Field: field.Name, Message: message})
+				break
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return &entities.ValidationError{Fields: errs}
+	}
+	return nil
+}
+
+// Required запрещает пустое значение и значение из одних пробелов
+func Required(value string) string {
+	if strings.TrimSpace(value) == "" {
+		return "обязательное поле"
+	}
+	return ""
+}
+
+// MaxLength ограничивает длину значения в символах, как VARCHAR(n) в DB
+func MaxLength(n int) Rule {
+	return func(value string) string {
+		if utf8.RuneCountInString(value) > n {
+			return fmt.Sprintf("не длиннее %d символов", n)
+		}
+		return ""
+	}
+}
+
+// URL требует абсолютную ссылку http или https. Пустое значение допускается.
+func URL(value string) string {
+	if value == "" {
+		return ""
+	}
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "ожидается ссылка http или https"
+	}
+	return ""
+}
+
+// releaseDateMessage перечисляет все форматы, которые принимает entities.ParseReleaseDate
+var releaseDateMessage = "ожидается дата в одном из форматов: " + strings.Join(entities.ReleaseDateFormats(), "; ")
+
+// ReleaseDate требует дату выпуска в одном из известных форматов. Пустое значение допускается.
+func ReleaseDate(value string) string {
+	if value == "" {
+		return ""
+	}
+	if _, err := entities.ParseReleaseDate(value); err != nil {
+		return releaseDateMessage
+	}
+	return ""
+}
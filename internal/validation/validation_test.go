@@ -0,0 +1,67 @@
+package validation
+
+import (
+	"TestEffectiveMobile/internal/entities"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSong(t *testing.T) {
+	tests := []struct {
+		name       string
+		song       entities.Song
+		wantFields []string
+	}{
+		{name: "корректная песня", song: entities.Song{Group: "Muse", Title: "Uprising", ReleaseDate: "16 Jul 2009"}},
+		{
+			name:       "все поля с ошибками в порядке правил",
+			song:       entities.Song{Group: " ", Title: strings.Repeat("я", maxTitleLength+1), ReleaseDate: "вчера", Link: "ftp://example.com"},
+			wantFields: []string{"group", "song", "releaseDate", "link"},
+		},
+		{
+			name:       "неверная дата",
+			song:       entities.Song{Group: "Muse", Title: "Uprising", ReleaseDate: "2009-13-01"},
+			wantFields: []string{"releaseDate"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Song(tt.song)
+			if got := errorFields(t, err); !reflect.DeepEqual(got, tt.wantFields) {
+				t.Errorf("поля с ошибками %v, ожидалось %v", got, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestReleaseDateMessage(t *testing.T) {
+	// Каждый формат из сообщения должен приниматься, иначе сообщение вводит клиента в заблуждение
+	for _, format := range entities.ReleaseDateFormats() {
+		if !strings.Contains(releaseDateMessage, format) {
+			t.Errorf("формат %q не указан в сообщении %q", format, releaseDateMessage)
+		}
+		if message := ReleaseDate(format); message != "" {
+			t.Errorf("ReleaseDate(%q) = %q", format, message)
+		}
+	}
+}
+
+// errorFields возвращает имена полей из *entities.ValidationError, nil без ошибки
+func errorFields(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var validationErr *entities.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("ошибка %v, ожидалась *entities.ValidationError", err)
+	}
+	var fields []string
+	for _, field := range validationErr.Fields {
+		fields = append(fields, field.Field)
+	}
+	return fields
+}
@@ -0,0 +1,11 @@
+ALTER TABLE songs ADD COLUMN group_name VARCHAR(255);
+
+UPDATE songs s SET group_name = a.name
+FROM artists a
+WHERE a.id = s.artist_id;
+
+ALTER TABLE songs ALTER COLUMN group_name SET NOT NULL;
+DROP INDEX IF EXISTS idx_songs_artist_id;
+ALTER TABLE songs DROP COLUMN artist_id;
+
+DROP TABLE IF EXISTS artists;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS artists (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE
+    );
+
+INSERT INTO artists (name)
+SELECT DISTINCT group_name FROM songs
+ON CONFLICT (name) DO NOTHING;
+
+ALTER TABLE songs ADD COLUMN artist_id INTEGER REFERENCES artists (id) ON DELETE RESTRICT;
+
+UPDATE songs s SET artist_id = a.id
+FROM artists a
+WHERE a.name = s.group_name;
+
+ALTER TABLE songs ALTER COLUMN artist_id SET NOT NULL;
+ALTER TABLE songs DROP COLUMN group_name;
+
+CREATE INDEX IF NOT EXISTS idx_songs_artist_id ON songs (artist_id);
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_songs_search_vector;
+
+DROP TRIGGER IF EXISTS artists_search_vector_trg ON artists;
+DROP FUNCTION IF EXISTS artists_search_vector_update();
+
+DROP TRIGGER IF EXISTS songs_search_vector_trg ON songs;
+DROP FUNCTION IF EXISTS songs_search_vector_update();
+
+ALTER TABLE songs DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,32 @@
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS search_vector tsvector;
+
+-- Вектор собирается из названия песни, названия группы и текста с разными весами
+CREATE OR REPLACE FUNCTION songs_search_vector_update() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('simple', coalesce(NEW.song_title, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce((SELECT name FROM artists WHERE id = NEW.artist_id), '')), 'B') ||
+        setweight(to_tsvector('simple', coalesce(NEW.text, '')), 'C');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER songs_search_vector_trg
+    BEFORE INSERT OR UPDATE OF artist_id, song_title, text ON songs
+    FOR EACH ROW EXECUTE FUNCTION songs_search_vector_update();
+
+-- При переименовании исполнителя пересчитываем вектор у всех его песен
+CREATE OR REPLACE FUNCTION artists_search_vector_update() RETURNS trigger AS $$
+BEGIN
+    UPDATE songs SET artist_id = artist_id WHERE artist_id = NEW.id;
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER artists_search_vector_trg
+    AFTER UPDATE OF name ON artists
+    FOR EACH ROW EXECUTE FUNCTION artists_search_vector_update();
+
+UPDATE songs SET artist_id = artist_id;
+
+CREATE INDEX IF NOT EXISTS idx_songs_search_vector ON songs USING GIN (search_vector);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_songs_song_title_trgm;
+DROP INDEX IF EXISTS idx_artists_name_trgm;
+
+DROP EXTENSION IF EXISTS pg_trgm;
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS songs_revision_trg ON songs;
+DROP FUNCTION IF EXISTS songs_revision_record();
+
+DROP TABLE IF EXISTS song_revisions;
@@ -0,0 +1,49 @@
+CREATE TABLE IF NOT EXISTS song_revisions (
+    id SERIAL PRIMARY KEY,
+    song_id INTEGER NOT NULL REFERENCES songs (id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    operation VARCHAR(16) NOT NULL,
+    group_name VARCHAR(255) NOT NULL,
+    data JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (song_id, revision)
+    );
+
+-- Снимок строки хранится целиком в JSONB, чтобы история переживала изменения схемы songs
+CREATE OR REPLACE FUNCTION songs_revision_record() RETURNS trigger AS $$
+DECLARE
+    operation VARCHAR(16);
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        operation := 'create';
+    ELSIF OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN
+        operation := 'delete';
+    ELSIF OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL THEN
+        operation := 'restore';
+    ELSIF (to_jsonb(NEW) - 'search_vector') = (to_jsonb(OLD) - 'search_vector') THEN
+        -- Служебные обновления (например, пересчёт поискового вектора) ревизий не создают
+        RETURN NULL;
+    ELSE
+        operation := 'update';
+    END IF;
+
+    INSERT INTO song_revisions (song_id, revision, operation, group_name, data)
+    VALUES (
+        NEW.id,
+        coalesce((SELECT max(revision) FROM song_revisions WHERE song_id = NEW.id), 0) + 1,
+        operation,
+        (SELECT name FROM artists WHERE id = NEW.artist_id),
+        to_jsonb(NEW) - 'search_vector'
+    );
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER songs_revision_trg
+    AFTER INSERT OR UPDATE ON songs
+    FOR EACH ROW EXECUTE FUNCTION songs_revision_record();
+
+-- Существующие песни получают первую ревизию
+INSERT INTO song_revisions (song_id, revision, operation, group_name, data)
+SELECT s.id, 1, 'create', a.name, to_jsonb(s) - 'search_vector'
+FROM songs s JOIN artists a ON a.id = s.artist_id;
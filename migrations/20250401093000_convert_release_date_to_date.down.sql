@@ -0,0 +1,17 @@
+ALTER TABLE songs DISABLE TRIGGER songs_revision_trg;
+
+DROP INDEX IF EXISTS idx_songs_release_date;
+
+ALTER TABLE songs ADD COLUMN release_date_old VARCHAR(50);
+
+UPDATE songs SET release_date_old = CASE release_date_precision
+    WHEN 'year' THEN to_char(release_date, 'YYYY')
+    WHEN 'month' THEN to_char(release_date, 'YYYY-MM')
+    ELSE to_char(release_date, 'YYYY-MM-DD') END
+WHERE release_date IS NOT NULL;
+
+ALTER TABLE songs DROP COLUMN release_date;
+ALTER TABLE songs DROP COLUMN release_date_precision;
+ALTER TABLE songs RENAME COLUMN release_date_old TO release_date;
+
+ALTER TABLE songs ENABLE TRIGGER songs_revision_trg;
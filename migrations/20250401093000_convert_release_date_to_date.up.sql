@@ -0,0 +1,113 @@
+-- Конвертация не должна создавать ревизию для каждой песни
+ALTER TABLE songs DISABLE TRIGGER songs_revision_trg;
+
+ALTER TABLE songs ADD COLUMN release_date_new DATE;
+ALTER TABLE songs ADD COLUMN release_date_precision VARCHAR(5)
+    CHECK (release_date_precision IN ('year', 'month', 'day'));
+
+-- Разбирает дату в любом из форматов entities.releaseDateLayouts.
+-- Для нераспознанного значения и несуществующей даты (31.02.2020, 2020-13) возвращает NULL.
+CREATE OR REPLACE FUNCTION parse_legacy_release_date(raw TEXT, OUT parsed DATE, OUT parsed_precision VARCHAR(5)) AS $$
+DECLARE
+    v TEXT := btrim(raw);
+    m TEXT[];
+    month_name TEXT;
+    month_number INTEGER;
+BEGIN
+    IF v ~ '^\d{4}-\d{2}-\d{2}(T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2}))?$' THEN
+        -- 2006-01-02 и RFC 3339
+        m := regexp_match(v, '^(\d{4})-(\d{2})-(\d{2})');
+        parsed := make_date(m[1]::int, m[2]::int, m[3]::int);
+        parsed_precision := 'day';
+    ELSIF v ~ '^\d{2}\.\d{2}\.\d{4}$' THEN
+        m := regexp_match(v, '^(\d{2})\.(\d{2})\.(\d{4})$');
+        parsed := make_date(m[3]::int, m[2]::int, m[1]::int);
+        parsed_precision := 'day';
+    ELSIF v ~ '^\d{4}/\d{2}/\d{2}$' THEN
+        m := regexp_match(v, '^(\d{4})/(\d{2})/(\d{2})$');
+        parsed := make_date(m[1]::int, m[2]::int, m[3]::int);
+        parsed_precision := 'day';
+    ELSIF v ~ '^\d{1,2} [A-Za-z]+ \d{4}$' THEN
+        -- 2 January 2006, 2 Jan 2006
+        m := regexp_match(v, '^(\d{1,2}) ([A-Za-z]+) (\d{4})$');
+        month_name := m[2];
+        parsed_precision := 'day';
+    ELSIF v ~ '^[A-Za-z]+ \d{1,2}, \d{4}$' THEN
+        -- January 2, 2006, Jan 2, 2006
+        m := regexp_match(v, '^([A-Za-z]+) (\d{1,2}), (\d{4})$');
+        month_name := m[1];
+        m := ARRAY[m[2], m[1], m[3]];
+        parsed_precision := 'day';
+    ELSIF v ~ '^\d{4}-\d{2}$' THEN
+        m := regexp_match(v, '^(\d{4})-(\d{2})$');
+        parsed := make_date(m[1]::int, m[2]::int, 1);
+        parsed_precision := 'month';
+    ELSIF v ~ '^\d{2}\.\d{4}$' THEN
+        m := regexp_match(v, '^(\d{2})\.(\d{4})$');
+        parsed := make_date(m[2]::int, m[1]::int, 1);
+        parsed_precision := 'month';
+    ELSIF v ~ '^[A-Za-z]+ \d{4}$' THEN
+        -- January 2006, Jan 2006
+        m := regexp_match(v, '^([A-Za-z]+) (\d{4})$');
+        month_name := m[1];
+        m := ARRAY['1', m[1], m[2]];
+        parsed_precision := 'month';
+    ELSIF v ~ '^\d{4}$' THEN
+        parsed := make_date(v::int, 1, 1);
+        parsed_precision := 'year';
+    END IF;
+
+    IF month_name IS NOT NULL THEN
+        month_number := coalesce(
+            array_position(ARRAY['january', 'february', 'march', 'april', 'may', 'june', 'july',
+                'august', 'september', 'october', 'november', 'december'], lower(month_name)),
+            array_position(ARRAY['jan', 'feb', 'mar', 'apr', 'may', 'jun', 'jul',
+                'aug', 'sep', 'oct', 'nov', 'dec'], lower(month_name)));
+        IF month_number IS NULL THEN
+            parsed := NULL;
+            parsed_precision := NULL;
+            RETURN;
+        END IF;
+        parsed := make_date(m[3]::int, month_number, m[1]::int);
+    END IF;
+EXCEPTION WHEN data_exception THEN
+    parsed := NULL;
+    parsed_precision := NULL;
+END
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+UPDATE songs SET (release_date_new, release_date_precision) =
+    (SELECT parsed, parsed_precision FROM parse_legacy_release_date(release_date))
+WHERE release_date IS NOT NULL;
+
+-- Значения, которые не удалось разобрать, пропали бы при удалении старой колонки.
+-- Миграция прерывается, такие даты нужно исправить вручную и запустить миграцию снова.
+DO $$
+DECLARE
+    lost INTEGER;
+    examples TEXT;
+BEGIN
+    SELECT count(*), string_agg(format('%s: %L', id, release_date), ', ' ORDER BY id) FILTER (WHERE rn <= 10)
+    INTO lost, examples
+    FROM (
+        SELECT id, release_date, row_number() OVER (ORDER BY id) AS rn
+        FROM songs
+        WHERE release_date_new IS NULL AND btrim(coalesce(release_date, '')) <> ''
+    ) unparsed;
+
+    IF lost > 0 THEN
+        RAISE EXCEPTION 'Не удалось разобрать дату выпуска у % песен, например (id: значение) %', lost, examples
+            USING HINT = 'Исправьте songs.release_date в одном из поддерживаемых форматов и повторите миграцию';
+    END IF;
+END
+$$;
+
+DROP FUNCTION parse_legacy_release_date(TEXT);
+
+DROP INDEX IF EXISTS idx_songs_release_date;
+ALTER TABLE songs DROP COLUMN release_date;
+ALTER TABLE songs RENAME COLUMN release_date_new TO release_date;
+
+CREATE INDEX IF NOT EXISTS idx_songs_release_date ON songs (release_date);
+
+ALTER TABLE songs ENABLE TRIGGER songs_revision_trg;
@@ -0,0 +1,21 @@
+DROP INDEX IF EXISTS idx_songs_artist_title_normalized;
+DROP INDEX IF EXISTS idx_artists_name_normalized;
+
+-- Песни, которые ещё лежат в корзине, возвращаются в библиотеку
+UPDATE songs s SET deleted_at = NULL, version = version + 1
+FROM dedup_trashed_songs t
+WHERE s.id = t.song_id AND s.deleted_at IS NOT NULL;
+
+-- Объединённые исполнители восстанавливаются, их песни возвращаются к ним,
+-- если после миграции группу песни не меняли
+INSERT INTO artists (id, name)
+SELECT artist_id, name FROM dedup_merged_artists
+ON CONFLICT DO NOTHING;
+
+UPDATE songs s SET artist_id = m.artist_id
+FROM dedup_merged_artists m
+JOIN artists a ON a.id = m.artist_id AND a.name = m.name
+WHERE s.id = ANY (m.song_ids) AND s.artist_id = m.merged_into;
+
+DROP TABLE IF EXISTS dedup_trashed_songs;
+DROP TABLE IF EXISTS dedup_merged_artists;
@@ -0,0 +1,52 @@
+-- Миграции применяются при запуске сервиса, поэтому прервать её и предложить очистку
+-- через GET /songs/duplicates нельзя: API недоступен, пока миграция не пройдёт.
+-- Вместо этого каждое изменение записывается в dedup_merged_artists и dedup_trashed_songs,
+-- по ним можно проверить затронутые записи, а down-миграция возвращает данные.
+CREATE TABLE IF NOT EXISTS dedup_merged_artists (
+    artist_id INTEGER PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    merged_into INTEGER NOT NULL,
+    song_ids INTEGER[] NOT NULL,
+    merged_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS dedup_trashed_songs (
+    song_id INTEGER PRIMARY KEY REFERENCES songs (id) ON DELETE CASCADE,
+    kept_song_id INTEGER NOT NULL,
+    trashed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Исполнители, отличающиеся только регистром или пробелами по краям, объединяются в самого раннего
+INSERT INTO dedup_merged_artists (artist_id, name, merged_into, song_ids)
+SELECT c.id, c.name, c.keep_id, coalesce(array_agg(s.id ORDER BY s.id) FILTER (WHERE s.id IS NOT NULL), '{}')
+FROM (SELECT id, name, min(id) OVER (PARTITION BY lower(btrim(name))) AS keep_id FROM artists) c
+LEFT JOIN songs s ON s.artist_id = c.id
+WHERE c.id <> c.keep_id
+GROUP BY c.id, c.name, c.keep_id;
+
+UPDATE songs s SET artist_id = m.merged_into
+FROM dedup_merged_artists m
+WHERE s.artist_id = m.artist_id;
+
+DELETE FROM artists a
+USING dedup_merged_artists m
+WHERE a.id = m.artist_id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_artists_name_normalized ON artists (lower(btrim(name)));
+
+-- Повторные песни перемещаются в корзину, в библиотеке остаётся самая ранняя
+INSERT INTO dedup_trashed_songs (song_id, kept_song_id)
+SELECT id, keep_id
+FROM (
+    SELECT id, min(id) OVER (PARTITION BY artist_id, lower(btrim(song_title))) AS keep_id
+    FROM songs
+    WHERE deleted_at IS NULL
+) c
+WHERE id <> keep_id;
+
+UPDATE songs s SET deleted_at = now(), version = version + 1
+FROM dedup_trashed_songs t
+WHERE s.id = t.song_id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_songs_artist_title_normalized
+    ON songs (artist_id, lower(btrim(song_title))) WHERE deleted_at IS NULL;
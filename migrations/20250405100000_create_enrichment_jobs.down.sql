@@ -0,0 +1,31 @@
+CREATE OR REPLACE FUNCTION songs_revision_record() RETURNS trigger AS $$
+DECLARE
+    operation VARCHAR(16);
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        operation := 'create';
+    ELSIF OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN
+        operation := 'delete';
+    ELSIF OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL THEN
+        operation := 'restore';
+    ELSIF (to_jsonb(NEW) - 'search_vector') = (to_jsonb(OLD) - 'search_vector') THEN
+        -- Служебные обновления (например, пересчёт поискового вектора) ревизий не создают
+        RETURN NULL;
+    ELSE
+        operation := 'update';
+    END IF;
+
+    INSERT INTO song_revisions (song_id, revision, operation, group_name, data)
+    VALUES (
+        NEW.id,
+        coalesce((SELECT max(revision) FROM song_revisions WHERE song_id = NEW.id), 0) + 1,
+        operation,
+        (SELECT name FROM artists WHERE id = NEW.artist_id),
+        to_jsonb(NEW) - 'search_vector'
+    );
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TABLE IF EXISTS enrichment_jobs;
+ALTER TABLE songs DROP COLUMN IF EXISTS enrichment_status;
@@ -0,0 +1,61 @@
+-- Уже сохранённые песни были обогащены синхронно при создании
+ALTER TABLE songs ADD COLUMN enrichment_status VARCHAR(16) NOT NULL DEFAULT 'done'
+    CHECK (enrichment_status IN ('pending', 'done', 'failed'));
+
+CREATE TABLE IF NOT EXISTS enrichment_jobs (
+    id SERIAL PRIMARY KEY,
+    song_id INTEGER NOT NULL REFERENCES songs (id) ON DELETE CASCADE,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'running', 'done', 'failed')),
+    -- overwrite: данные внешнего API заменяют заполненные поля, а не только пустые
+    overwrite BOOLEAN NOT NULL DEFAULT false,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    run_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    -- locked_until: срок, до которого задачу обрабатывает воркер. Задачу упавшего воркера
+    -- после этого срока забирает другой
+    locked_until TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    );
+
+-- У песни не больше одной незавершённой задачи
+CREATE UNIQUE INDEX IF NOT EXISTS idx_enrichment_jobs_song_active
+    ON enrichment_jobs (song_id) WHERE status IN ('pending', 'running');
+
+CREATE INDEX IF NOT EXISTS idx_enrichment_jobs_run_at
+    ON enrichment_jobs (run_at) WHERE status IN ('pending', 'running');
+
+CREATE INDEX IF NOT EXISTS idx_enrichment_jobs_failed
+    ON enrichment_jobs (updated_at DESC) WHERE status = 'failed';
+
+-- Смена статуса обогащения сама по себе ревизию не создаёт
+CREATE OR REPLACE FUNCTION songs_revision_record() RETURNS trigger AS $$
+DECLARE
+    operation VARCHAR(16);
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        operation := 'create';
+    ELSIF OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN
+        operation := 'delete';
+    ELSIF OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL THEN
+        operation := 'restore';
+    ELSIF (to_jsonb(NEW) - 'search_vector' - 'enrichment_status')
+        = (to_jsonb(OLD) - 'search_vector' - 'enrichment_status') THEN
+        -- Служебные обновления (например, пересчёт поискового вектора) ревизий не создают
+        RETURN NULL;
+    ELSE
+        operation := 'update';
+    END IF;
+
+    INSERT INTO song_revisions (song_id, revision, operation, group_name, data)
+    VALUES (
+        NEW.id,
+        coalesce((SELECT max(revision) FROM song_revisions WHERE song_id = NEW.id), 0) + 1,
+        operation,
+        (SELECT name FROM artists WHERE id = NEW.artist_id),
+        to_jsonb(NEW) - 'search_vector' - 'enrichment_status'
+    );
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
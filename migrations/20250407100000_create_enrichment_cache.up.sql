@@ -0,0 +1,16 @@
+-- Ответы источников обогащения. found = false запоминает, что песня не найдена (404)
+CREATE TABLE IF NOT EXISTS enrichment_cache (
+    id SERIAL PRIMARY KEY,
+    group_name VARCHAR(255) NOT NULL,
+    song_title VARCHAR(255) NOT NULL,
+    found BOOLEAN NOT NULL,
+    release_date TEXT NOT NULL DEFAULT '',
+    text TEXT NOT NULL DEFAULT '',
+    link TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at TIMESTAMPTZ NOT NULL
+    );
+
+-- Группа и название сравниваются без учёта регистра и пробелов по краям, как у песен и исполнителей
+CREATE UNIQUE INDEX IF NOT EXISTS idx_enrichment_cache_key
+    ON enrichment_cache ((lower(btrim(group_name))), (lower(btrim(song_title))));
@@ -0,0 +1,46 @@
+-- Последний номер ревизии каждой песни. Номер выдаёт атомарный upsert счётчика,
+-- поэтому одновременные изменения одной песни не получают одинаковый номер
+CREATE TABLE IF NOT EXISTS song_revision_counters (
+    song_id INTEGER PRIMARY KEY REFERENCES songs (id) ON DELETE CASCADE,
+    last_revision INTEGER NOT NULL
+    );
+
+INSERT INTO song_revision_counters (song_id, last_revision)
+SELECT song_id, max(revision) FROM song_revisions GROUP BY song_id
+ON CONFLICT (song_id) DO NOTHING;
+
+CREATE OR REPLACE FUNCTION songs_revision_record() RETURNS trigger AS $$
+DECLARE
+    operation VARCHAR(16);
+    next_revision INTEGER;
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        operation := 'create';
+    ELSIF OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN
+        operation := 'delete';
+    ELSIF OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL THEN
+        operation := 'restore';
+    ELSIF (to_jsonb(NEW) - 'search_vector' - 'enrichment_status')
+        = (to_jsonb(OLD) - 'search_vector' - 'enrichment_status') THEN
+        -- Служебные обновления (например, пересчёт поискового вектора) ревизий не создают
+        RETURN NULL;
+    ELSE
+        operation := 'update';
+    END IF;
+
+    INSERT INTO song_revision_counters AS c (song_id, last_revision)
+    VALUES (NEW.id, 1)
+    ON CONFLICT (song_id) DO UPDATE SET last_revision = c.last_revision + 1
+    RETURNING last_revision INTO next_revision;
+
+    INSERT INTO song_revisions (song_id, revision, operation, group_name, data)
+    VALUES (
+        NEW.id,
+        next_revision,
+        operation,
+        (SELECT name FROM artists WHERE id = NEW.artist_id),
+        to_jsonb(NEW) - 'search_vector' - 'enrichment_status'
+    );
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
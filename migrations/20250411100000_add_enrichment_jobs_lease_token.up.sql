@@ -0,0 +1,5 @@
+-- lease_token: номер выдачи задачи воркеру. ClaimJob увеличивает его при каждой выдаче, а воркер
+-- завершает или откладывает задачу, только пока номер не сменился. Так воркер, не уложившийся
+-- в срок, не перезапишет результат воркера, забравшего задачу после него.
+-- attempts для этого не подходит: RetryJob обнуляет его.
+ALTER TABLE enrichment_jobs ADD COLUMN IF NOT EXISTS lease_token BIGINT NOT NULL DEFAULT 0;